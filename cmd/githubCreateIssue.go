@@ -2,9 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/SAP/jenkins-library/pkg/orchestrator"
 	"github.com/SAP/jenkins-library/pkg/piperutils"
+	"github.com/SAP/jenkins-library/pkg/reporting"
 	"github.com/SAP/jenkins-library/pkg/telemetry"
 	"github.com/pkg/errors"
 
@@ -19,6 +24,9 @@ type githubCreateIssueUtils interface {
 func githubCreateIssue(config githubCreateIssueOptions, telemetryData *telemetry.CustomData) {
 	fileUtils := &piperutils.Files{}
 	options := piperGithub.CreateIssueOptions{}
+	if len(config.CommentTemplate) > 0 {
+		options.CommentValues = commentValues()
+	}
 	err := runGithubCreateIssue(&config, telemetryData, &options, fileUtils, piperGithub.CreateIssue)
 	if err != nil {
 		log.Entry().WithError(err).Fatal("Failed to comment on issue")
@@ -31,15 +39,30 @@ func runGithubCreateIssue(config *githubCreateIssueOptions, _ *telemetry.CustomD
 		return err
 	}
 	transformConfig(config, options, chunks[0])
+	if len(config.HttpTimeout) > 0 {
+		if options.HTTPTimeout, err = time.ParseDuration(config.HttpTimeout); err != nil {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return fmt.Errorf("invalid HTTP timeout '%v': %w", config.HttpTimeout, err)
+		}
+	}
 	issue, err := createIssue(options)
 	if err != nil {
 		return err
 	}
+	if options.Existing && (options.UpdateMode == piperGithub.UpdateModeReaction || options.UpdateMode == piperGithub.UpdateModeNone) {
+		// the body has not been added to the existing issue, thus also the remaining chunks are skipped
+		return nil
+	}
 	if len(chunks) > 1 {
 		for _, v := range chunks[1:] {
 			options.Body = []byte(v)
 			options.Issue = issue
 			options.UpdateExisting = true
+			options.UpdateMode = piperGithub.UpdateModeComment
+			// the issue has been linked to the parent issue already
+			options.ParentIssue = 0
+			// the remaining chunks continue the first comment
+			options.CommentTemplate = ""
 			_, err = createIssue(options)
 			if err != nil {
 				return err
@@ -49,32 +72,120 @@ func runGithubCreateIssue(config *githubCreateIssueOptions, _ *telemetry.CustomD
 	return nil
 }
 
+// githubUserAgent identifies the piper version and step in requests to GitHub, e.g. for GitHub support
+func githubUserAgent(stepName string) string {
+	version := "n/a"
+	if len(GitTag) > 0 {
+		version = GitTag
+	} else if len(GitCommit) > 0 {
+		version = GitCommit
+	}
+	return fmt.Sprintf("piper/%v (%v)", version, stepName)
+}
+
+// commentValues returns the context of the pipeline run which is available in the comment template
+func commentValues() map[string]string {
+	values := map[string]string{"Timestamp": time.Now().UTC().Format(time.RFC3339)}
+	provider, err := orchestrator.NewOrchestratorSpecificConfigProvider()
+	if err != nil {
+		log.Entry().WithError(err).Warn("Failed to get orchestrator information for the comment template")
+		return values
+	}
+	values["Pipeline"] = provider.GetJobName()
+	values["BuildURL"] = provider.GetBuildURL()
+	values["Stage"] = provider.GetStageName()
+	values["Branch"] = provider.GetBranch()
+	values["Commit"] = provider.GetCommit()
+	return values
+}
+
 func getBody(config *githubCreateIssueOptions, readFile func(string) ([]byte, error)) ([]string, error) {
 	var bodyString []rune
-	if len(config.Body)+len(config.BodyFilePath) == 0 {
-		return nil, fmt.Errorf("either parameter `body` or parameter `bodyFilePath` is required")
+	if len(config.Body)+len(config.BodyFilePath)+len(config.BodyCPEKey)+len(config.ReportFilePath) == 0 {
+		return nil, fmt.Errorf("one of the parameters `body`, `bodyFilePath`, `bodyCPEKey` or `reportFilePath` is required")
 	}
-	if len(config.Body) == 0 {
+	if len(config.Body) > 0 {
+		bodyString = []rune(config.Body)
+	} else if len(config.BodyFilePath) > 0 {
 		issueContent, err := readFile(config.BodyFilePath)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to read file '%v'", config.BodyFilePath)
 		}
 		bodyString = []rune(string(issueContent))
+	} else if len(config.BodyCPEKey) > 0 {
+		// the value is read like piperenv.GetParameter does, but via readFile in order to fail on a missing value
+		issueContent, err := readFile(filepath.Join(GeneralConfig.EnvRootPath, "commonPipelineEnvironment", config.BodyCPEKey))
+		if err != nil {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return nil, errors.Wrapf(err, "failed to read value '%v' of the commonPipelineEnvironment", config.BodyCPEKey)
+		}
+		bodyString = []rune(strings.TrimSpace(string(issueContent)))
 	} else {
-		bodyString = []rune(config.Body)
+		reportContent, err := readFile(config.ReportFilePath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read file '%v'", config.ReportFilePath)
+		}
+		report, err := reporting.ParseIssueDetail(reportContent)
+		if err != nil {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return nil, errors.Wrapf(err, "failed to read report '%v'", config.ReportFilePath)
+		}
+		issueContent, err := report.ToMarkdown()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to render report '%v'", config.ReportFilePath)
+		}
+		bodyString = []rune(string(issueContent))
+	}
+	if config.MaxBodyBytes > 0 {
+		maxBodyBytes := piperGithub.MaxIssueBodySize
+		if len(config.Fingerprint) > 0 {
+			// the fingerprint marker is appended to the body of a new issue
+			maxBodyBytes -= len(piperGithub.FingerprintMarker(config.Fingerprint))
+		}
+		if config.MaxBodyBytes > maxBodyBytes {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return nil, fmt.Errorf("maxBodyBytes must not exceed %v bytes, GitHub does not accept larger issue bodies", maxBodyBytes)
+		}
+		// bodies exceeding the maximum size are moved into a gist instead of being split into comments
+		return []string{string(bodyString)}, nil
+	}
+	chunkSize := config.ChunkSize
+	if len(config.Fingerprint) > 0 {
+		// the first chunk is extended by the fingerprint marker and must still fit into the issue body
+		markerSize := len([]rune(piperGithub.FingerprintMarker(config.Fingerprint)))
+		if chunkSize <= markerSize {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return nil, fmt.Errorf("chunkSize must be larger than %v, the size of the fingerprint marker", markerSize)
+		}
+		chunkSize -= markerSize
+	}
+	if chunkSize <= 0 {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return nil, errors.New("chunkSize must be larger than 0")
 	}
-	return getChunks(bodyString, config.ChunkSize), nil
+	return getChunks(bodyString, chunkSize), nil
 }
 
 func transformConfig(config *githubCreateIssueOptions, options *piperGithub.CreateIssueOptions, body string) {
 	options.Token = config.Token
 	options.APIURL = config.APIURL
+	options.UploadURL = config.UploadURL
+	options.UserAgent = githubUserAgent("githubCreateIssue")
 	options.Owner = config.Owner
 	options.Repository = config.Repository
 	options.Title = config.Title
 	options.Body = []byte(config.Body)
 	options.Assignees = config.Assignees
 	options.UpdateExisting = config.UpdateExisting
+	options.UpdateMode = config.UpdateMode
+	options.Target = config.Target
+	options.DiscussionCategory = config.DiscussionCategory
+	options.Fingerprint = config.Fingerprint
+	options.MaxBodyBytes = config.MaxBodyBytes
+	options.CommentTemplate = config.CommentTemplate
+	options.IssueNumber = config.IssueNumber
+	options.ReopenClosed = config.ReopenClosed
+	options.ParentIssue = config.ParentIssue
 	options.Body = []byte(body)
 }
 
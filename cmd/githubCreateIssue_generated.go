@@ -16,16 +16,29 @@ import (
 )
 
 type githubCreateIssueOptions struct {
-	APIURL         string   `json:"apiUrl,omitempty"`
-	Assignees      []string `json:"assignees,omitempty"`
-	ChunkSize      int      `json:"chunkSize,omitempty"`
-	Body           string   `json:"body,omitempty"`
-	BodyFilePath   string   `json:"bodyFilePath,omitempty"`
-	Owner          string   `json:"owner,omitempty"`
-	Repository     string   `json:"repository,omitempty"`
-	Title          string   `json:"title,omitempty"`
-	UpdateExisting bool     `json:"updateExisting,omitempty"`
-	Token          string   `json:"token,omitempty"`
+	APIURL             string   `json:"apiUrl,omitempty"`
+	UploadURL          string   `json:"uploadUrl,omitempty"`
+	HttpTimeout        string   `json:"httpTimeout,omitempty"`
+	Assignees          []string `json:"assignees,omitempty"`
+	ChunkSize          int      `json:"chunkSize,omitempty"`
+	MaxBodyBytes       int      `json:"maxBodyBytes,omitempty"`
+	Body               string   `json:"body,omitempty"`
+	BodyFilePath       string   `json:"bodyFilePath,omitempty"`
+	BodyCPEKey         string   `json:"bodyCPEKey,omitempty"`
+	ReportFilePath     string   `json:"reportFilePath,omitempty"`
+	Owner              string   `json:"owner,omitempty"`
+	Repository         string   `json:"repository,omitempty"`
+	Title              string   `json:"title,omitempty"`
+	UpdateExisting     bool     `json:"updateExisting,omitempty"`
+	IssueNumber        int      `json:"issueNumber,omitempty"`
+	ReopenClosed       bool     `json:"reopenClosed,omitempty"`
+	UpdateMode         string   `json:"updateMode,omitempty" validate:"possible-values=comment reaction none"`
+	CommentTemplate    string   `json:"commentTemplate,omitempty"`
+	Target             string   `json:"target,omitempty" validate:"possible-values=issue discussion"`
+	DiscussionCategory string   `json:"discussionCategory,omitempty"`
+	ParentIssue        int      `json:"parentIssue,omitempty"`
+	Fingerprint        string   `json:"fingerprint,omitempty"`
+	Token              string   `json:"token,omitempty"`
 }
 
 // GithubCreateIssueCommand Create a new GitHub issue.
@@ -125,14 +138,27 @@ You will be able to use this step for example for regular jobs to report into yo
 
 func addGithubCreateIssueFlags(cmd *cobra.Command, stepConfig *githubCreateIssueOptions) {
 	cmd.Flags().StringVar(&stepConfig.APIURL, "apiUrl", `https://api.github.com`, "Set the GitHub API url.")
+	cmd.Flags().StringVar(&stepConfig.UploadURL, "uploadUrl", `https://uploads.github.com`, "Set the GitHub upload url used for uploading assets. GitHub Enterprise Server provides it at a different path than the API,\ntypically `https://<host>/api/uploads`.")
+	cmd.Flags().StringVar(&stepConfig.HttpTimeout, "httpTimeout", `30s`, "Defines the maximum duration of a request to GitHub as duration, e.g. `30s`, so that a hanging request does not block the pipeline.\nAn empty value disables the timeout.")
 	cmd.Flags().StringSliceVar(&stepConfig.Assignees, "assignees", []string{``}, "Defines the assignees for the Issue.")
 	cmd.Flags().IntVar(&stepConfig.ChunkSize, "chunkSize", 65500, "Defines size of the chunk. If content exceed chunk size it'll be sliced into chunks and stored in comments")
+	cmd.Flags().IntVar(&stepConfig.MaxBodyBytes, "maxBodyBytes", 0, "Defines the maximum size of the body in bytes. If the content exceeds this size, it is stored in a secret gist and the body only contains the beginning of the content and a link to the gist.\nThis replaces the splitting into comments as defined by [`chunkSize`](#chunksize). The token requires the `gist` scope for this.\nA value of `0` disables this behavior. The value must not exceed 65536, the maximum size of an issue body accepted by GitHub.")
 	cmd.Flags().StringVar(&stepConfig.Body, "body", os.Getenv("PIPER_body"), "Defines the content of the issue, e.g. using markdown syntax.")
 	cmd.Flags().StringVar(&stepConfig.BodyFilePath, "bodyFilePath", os.Getenv("PIPER_bodyFilePath"), "Defines the path to a file containing the markdown content for the issue. This can be used instead of [`body`](#body)")
+	cmd.Flags().StringVar(&stepConfig.BodyCPEKey, "bodyCPEKey", os.Getenv("PIPER_bodyCPEKey"), "Defines the key of a value in the commonPipelineEnvironment containing the markdown content for the issue, e.g. `custom/findingReport` written by a previous step.\nThis avoids writing an intermediate file just to pass the content between steps. It is only used in case neither [`body`](#body) nor [`bodyFilePath`](#bodyfilepath) is set.")
+	cmd.Flags().StringVar(&stepConfig.ReportFilePath, "reportFilePath", os.Getenv("PIPER_reportFilePath"), "Defines the path to a JSON report written by another step which is used as content of the issue in case neither `body`, `bodyFilePath` nor `bodyCPEKey` is set,\ne.g. the `diffReportFile` of the step `helmExecute`.")
 	cmd.Flags().StringVar(&stepConfig.Owner, "owner", os.Getenv("PIPER_owner"), "Name of the GitHub organization.")
 	cmd.Flags().StringVar(&stepConfig.Repository, "repository", os.Getenv("PIPER_repository"), "Name of the GitHub repository.")
 	cmd.Flags().StringVar(&stepConfig.Title, "title", os.Getenv("PIPER_title"), "Defines the title for the Issue.")
 	cmd.Flags().BoolVar(&stepConfig.UpdateExisting, "updateExisting", false, "Whether to update an existing open issue with the same title by adding a comment instead of creating a new one.")
+	cmd.Flags().IntVar(&stepConfig.IssueNumber, "issueNumber", 0, "Defines the number of an existing issue which is updated as defined by [`updateMode`](#updatemode) instead of searching for an issue with the same title or [`fingerprint`](#fingerprint).\nThe step fails if the issue does not exist. A value of `0` disables this behavior.")
+	cmd.Flags().BoolVar(&stepConfig.ReopenClosed, "reopenClosed", false, "Whether to reopen the issue defined by [`issueNumber`](#issuenumber) in case it is closed. Otherwise a closed issue is updated without reopening it.")
+	cmd.Flags().StringVar(&stepConfig.UpdateMode, "updateMode", `comment`, "Defines how an existing issue is updated in case [`updateExisting`](#updateexisting) is active.\n`comment` adds the body as comment, `reaction` only adds an :eyes: reaction to the latest comment of the issue written by the user of the token (or the issue itself if there is no such comment)\nand `none` leaves the existing issue untouched. The latter two avoid flooding the issue in frequently running pipelines.")
+	cmd.Flags().StringVar(&stepConfig.CommentTemplate, "commentTemplate", os.Getenv("PIPER_commentTemplate"), "Defines the comment which is added to an existing issue or discussion in update mode `comment` as [Go template](https://pkg.go.dev/text/template), e.g.\n`Found by [{{.Pipeline}}]({{.BuildURL}}) at {{.Timestamp}} for commit {{.Commit}}: {{.Body}}`.\nAvailable values are `Body`, `Pipeline`, `BuildURL`, `Stage`, `Branch`, `Commit` and `Timestamp` (UTC). By default the comment only contains the body.")
+	cmd.Flags().StringVar(&stepConfig.Target, "target", `issue`, "Defines whether a GitHub issue or a GitHub discussion is created.\nFor discussions, [`updateExisting`](#updateexisting), [`updateMode`](#updatemode), `title` and `body` are applied in the same way as for issues.")
+	cmd.Flags().StringVar(&stepConfig.DiscussionCategory, "discussionCategory", `General`, "Name of the discussion category in which a new discussion is created. Only used in case [`target`](#target) is `discussion`.")
+	cmd.Flags().IntVar(&stepConfig.ParentIssue, "parentIssue", 0, "Defines the number of a parent issue in the same repository, e.g. a tracking issue of a scan.\nThe created issue is added to the body of the parent issue as task list item (`- [ ] #<number>`), so that GitHub tracks it in the parent issue.\nA missing parent issue only results in a warning. A value of `0` disables this behavior.\nThe parent issue is not supported for the target `discussion`.")
+	cmd.Flags().StringVar(&stepConfig.Fingerprint, "fingerprint", os.Getenv("PIPER_fingerprint"), "Identifies the issue independent of its title. A hidden marker (`<!-- piper-issue-id: <hash> -->`) derived from the fingerprint is added to the body of a new issue.\nWith [`updateExisting`](#updateexisting), existing issues are first searched by this marker and only then by title, so that the title of the issue can be edited.\nThe fingerprint is not supported for the target `discussion`.")
 	cmd.Flags().StringVar(&stepConfig.Token, "token", os.Getenv("PIPER_token"), "GitHub personal access token as per https://help.github.com/en/github/authenticating-to-github/creating-a-personal-access-token-for-the-command-line.")
 
 	cmd.MarkFlagRequired("apiUrl")
@@ -165,6 +191,24 @@ func githubCreateIssueMetadata() config.StepData {
 						Aliases:     []config.Alias{{Name: "githubApiUrl"}},
 						Default:     `https://api.github.com`,
 					},
+					{
+						Name:        "uploadUrl",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"GENERAL", "PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{{Name: "githubUploadUrl"}},
+						Default:     `https://uploads.github.com`,
+					},
+					{
+						Name:        "httpTimeout",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     `30s`,
+					},
 					{
 						Name:        "assignees",
 						ResourceRef: []config.ResourceReference{},
@@ -183,6 +227,15 @@ func githubCreateIssueMetadata() config.StepData {
 						Aliases:     []config.Alias{},
 						Default:     65500,
 					},
+					{
+						Name:        "maxBodyBytes",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "int",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     0,
+					},
 					{
 						Name:        "body",
 						ResourceRef: []config.ResourceReference{},
@@ -201,6 +254,24 @@ func githubCreateIssueMetadata() config.StepData {
 						Aliases:     []config.Alias{},
 						Default:     os.Getenv("PIPER_bodyFilePath"),
 					},
+					{
+						Name:        "bodyCPEKey",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_bodyCPEKey"),
+					},
+					{
+						Name:        "reportFilePath",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_reportFilePath"),
+					},
 					{
 						Name: "owner",
 						ResourceRef: []config.ResourceReference{
@@ -247,6 +318,78 @@ func githubCreateIssueMetadata() config.StepData {
 						Aliases:     []config.Alias{},
 						Default:     false,
 					},
+					{
+						Name:        "issueNumber",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "int",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     0,
+					},
+					{
+						Name:        "reopenClosed",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "updateMode",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     `comment`,
+					},
+					{
+						Name:        "commentTemplate",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_commentTemplate"),
+					},
+					{
+						Name:        "target",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     `issue`,
+					},
+					{
+						Name:        "discussionCategory",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     `General`,
+					},
+					{
+						Name:        "parentIssue",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "int",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     0,
+					},
+					{
+						Name:        "fingerprint",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_fingerprint"),
+					},
 					{
 						Name: "token",
 						ResourceRef: []config.ResourceReference{
@@ -4,12 +4,17 @@
 package cmd
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	piperGithub "github.com/SAP/jenkins-library/pkg/github"
 	"github.com/SAP/jenkins-library/pkg/mock"
+	"github.com/SAP/jenkins-library/pkg/reporting"
 	github "github.com/google/go-github/v45/github"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetChunk(t *testing.T) {
@@ -64,6 +69,7 @@ func TestTransformConfig(t *testing.T) {
 		// init
 		filesMock := mock.FilesMock{}
 		config := githubCreateIssueOptions{
+			UploadURL:  "https://github.example.com/api/uploads",
 			Owner:      "TEST",
 			Repository: "test",
 			Body:       "This is my test body",
@@ -85,6 +91,8 @@ func TestTransformConfig(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, config.Token, options.Token)
 		assert.Equal(t, config.APIURL, options.APIURL)
+		assert.Equal(t, config.UploadURL, options.UploadURL)
+		assert.Equal(t, "piper/n/a (githubCreateIssue)", options.UserAgent)
 		assert.Equal(t, config.Owner, options.Owner)
 		assert.Equal(t, config.Repository, options.Repository)
 		assert.Equal(t, config.Title, options.Title)
@@ -118,6 +126,7 @@ func TestTransformConfig(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, config.Token, options.Token)
 		assert.Equal(t, config.APIURL, options.APIURL)
+		assert.Equal(t, config.UploadURL, options.UploadURL)
 		assert.Equal(t, config.Owner, options.Owner)
 		assert.Equal(t, config.Repository, options.Repository)
 		assert.Equal(t, config.Title, options.Title)
@@ -126,6 +135,176 @@ func TestTransformConfig(t *testing.T) {
 		assert.ElementsMatch(t, resultChunks, []string{"Test markdown"})
 	})
 
+	t.Run("Success bodyCPEKey", func(t *testing.T) {
+		// init
+		filesMock := mock.FilesMock{}
+		filesMock.AddFile(filepath.Join(GeneralConfig.EnvRootPath, "commonPipelineEnvironment", "custom", "findingReport"), []byte("## Findings\n"))
+		config := githubCreateIssueOptions{
+			BodyCPEKey:     "custom/findingReport",
+			ReportFilePath: "report.json",
+			Title:          "This is my title",
+			ChunkSize:      100,
+		}
+		options := piperGithub.CreateIssueOptions{}
+		resultChunks := []string{}
+		createIssue := func(options *piperGithub.CreateIssueOptions) (*github.Issue, error) {
+			resultChunks = append(resultChunks, string(options.Body))
+			return nil, nil
+		}
+
+		// test
+		err := runGithubCreateIssue(&config, nil, &options, &filesMock, createIssue)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"## Findings"}, resultChunks)
+	})
+
+	t.Run("Success body and bodyFilePath take precedence over bodyCPEKey", func(t *testing.T) {
+		// init
+		filesMock := mock.FilesMock{}
+		filesMock.AddFile("test.md", []byte("Test markdown"))
+		filesMock.AddFile(filepath.Join(GeneralConfig.EnvRootPath, "commonPipelineEnvironment", "custom", "findingReport"), []byte("## Findings"))
+		resultChunks := []string{}
+		createIssue := func(options *piperGithub.CreateIssueOptions) (*github.Issue, error) {
+			resultChunks = append(resultChunks, string(options.Body))
+			return nil, nil
+		}
+
+		// test
+		config := githubCreateIssueOptions{Body: "This is my test body", BodyFilePath: "test.md", BodyCPEKey: "custom/findingReport", ChunkSize: 100}
+		err := runGithubCreateIssue(&config, nil, &piperGithub.CreateIssueOptions{}, &filesMock, createIssue)
+		assert.NoError(t, err)
+		config = githubCreateIssueOptions{BodyFilePath: "test.md", BodyCPEKey: "custom/findingReport", ChunkSize: 100}
+		err = runGithubCreateIssue(&config, nil, &piperGithub.CreateIssueOptions{}, &filesMock, createIssue)
+		assert.NoError(t, err)
+
+		// assert
+		assert.Equal(t, []string{"This is my test body", "Test markdown"}, resultChunks)
+	})
+
+	t.Run("Error - missing bodyCPEKey value", func(t *testing.T) {
+		// init
+		filesMock := mock.FilesMock{}
+		config := githubCreateIssueOptions{BodyCPEKey: "custom/findingReport", ChunkSize: 100}
+		options := piperGithub.CreateIssueOptions{}
+		createIssue := func(options *piperGithub.CreateIssueOptions) (*github.Issue, error) {
+			return nil, nil
+		}
+
+		// test
+		err := runGithubCreateIssue(&config, nil, &options, &filesMock, createIssue)
+
+		// assert
+		assert.ErrorContains(t, err, "failed to read value 'custom/findingReport' of the commonPipelineEnvironment")
+	})
+
+	t.Run("Success parent issue and comment template used by first chunk only", func(t *testing.T) {
+		// init
+		filesMock := mock.FilesMock{}
+		config := githubCreateIssueOptions{
+			Body:            "1234567890",
+			ChunkSize:       5,
+			ParentIssue:     7,
+			CommentTemplate: "{{.Commit}}: {{.Body}}",
+		}
+		options := piperGithub.CreateIssueOptions{}
+		parentIssues := []int{}
+		commentTemplates := []string{}
+		createIssue := func(options *piperGithub.CreateIssueOptions) (*github.Issue, error) {
+			parentIssues = append(parentIssues, options.ParentIssue)
+			commentTemplates = append(commentTemplates, options.CommentTemplate)
+			return &github.Issue{}, nil
+		}
+
+		// test
+		err := runGithubCreateIssue(&config, nil, &options, &filesMock, createIssue)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []int{7, 0}, parentIssues)
+		assert.Equal(t, []string{"{{.Commit}}: {{.Body}}", ""}, commentTemplates)
+	})
+
+	t.Run("Success HTTP timeout", func(t *testing.T) {
+		// init
+		filesMock := mock.FilesMock{}
+		config := githubCreateIssueOptions{Body: "body", ChunkSize: 100, HttpTimeout: "30s"}
+		options := piperGithub.CreateIssueOptions{}
+		createIssue := func(options *piperGithub.CreateIssueOptions) (*github.Issue, error) {
+			return nil, nil
+		}
+
+		// test
+		err := runGithubCreateIssue(&config, nil, &options, &filesMock, createIssue)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, 30*time.Second, options.HTTPTimeout)
+	})
+
+	t.Run("Error - invalid HTTP timeout", func(t *testing.T) {
+		// init
+		filesMock := mock.FilesMock{}
+		config := githubCreateIssueOptions{Body: "body", ChunkSize: 100, HttpTimeout: "30"}
+		options := piperGithub.CreateIssueOptions{}
+		createIssue := func(options *piperGithub.CreateIssueOptions) (*github.Issue, error) {
+			return nil, nil
+		}
+
+		// test
+		err := runGithubCreateIssue(&config, nil, &options, &filesMock, createIssue)
+
+		// assert
+		assert.EqualError(t, err, "invalid HTTP timeout '30': time: missing unit in duration \"30\"")
+	})
+
+	t.Run("Success reportFilePath", func(t *testing.T) {
+		// init
+		filesMock := mock.FilesMock{}
+		report, err := reporting.NewHelmDiffReport("my-app", "dev", "./chart", "+  replicas: 2\n").ToJSON()
+		require.NoError(t, err)
+		filesMock.AddFile("helm-diff.json", report)
+		config := githubCreateIssueOptions{
+			ReportFilePath: "helm-diff.json",
+			Title:          "Deployment changes",
+			ChunkSize:      1000,
+		}
+		options := piperGithub.CreateIssueOptions{}
+		resultChunks := []string{}
+		createIssue := func(options *piperGithub.CreateIssueOptions) (*github.Issue, error) {
+			resultChunks = append(resultChunks, string(options.Body))
+			return nil, nil
+		}
+
+		// test
+		err = runGithubCreateIssue(&config, nil, &options, &filesMock, createIssue)
+
+		// assert
+		assert.NoError(t, err)
+		if assert.Len(t, resultChunks, 1) {
+			assert.Contains(t, resultChunks[0], "## Helm diff of release my-app in namespace dev")
+			assert.Contains(t, resultChunks[0], "```diff\n+  replicas: 2\n```")
+		}
+	})
+
+	t.Run("Error - unsupported report", func(t *testing.T) {
+		// init
+		filesMock := mock.FilesMock{}
+		filesMock.AddFile("report.json", []byte(`{"type": "unknown"}`))
+		config := githubCreateIssueOptions{ReportFilePath: "report.json", ChunkSize: 1000}
+		options := piperGithub.CreateIssueOptions{}
+		createIssue := func(options *piperGithub.CreateIssueOptions) (*github.Issue, error) {
+			return nil, nil
+		}
+
+		// test
+		err := runGithubCreateIssue(&config, nil, &options, &filesMock, createIssue)
+
+		// assert
+		assert.EqualError(t, err, "failed to read report 'report.json': unsupported report type 'unknown'")
+	})
+
 	t.Run("Error - missing issue body", func(t *testing.T) {
 		// init
 		filesMock := mock.FilesMock{}
@@ -140,6 +319,93 @@ func TestTransformConfig(t *testing.T) {
 		err := runGithubCreateIssue(&config, nil, &options, &filesMock, createIssue)
 
 		// assert
-		assert.EqualError(t, err, "either parameter `body` or parameter `bodyFilePath` is required")
+		assert.EqualError(t, err, "one of the parameters `body`, `bodyFilePath`, `bodyCPEKey` or `reportFilePath` is required")
+	})
+}
+
+func TestRunGithubCreateIssueUpdateMode(t *testing.T) {
+	t.Parallel()
+
+	config := githubCreateIssueOptions{
+		Owner:          "TEST",
+		Repository:     "test",
+		Body:           "The quick brown fox",
+		Title:          "This is my title",
+		ChunkSize:      10,
+		UpdateExisting: true,
+		UpdateMode:     "reaction",
+	}
+	options := piperGithub.CreateIssueOptions{}
+	calls := 0
+	createIssue := func(options *piperGithub.CreateIssueOptions) (*github.Issue, error) {
+		calls++
+		options.Existing = true
+		return &github.Issue{}, nil
+	}
+
+	err := runGithubCreateIssue(&config, nil, &options, &mock.FilesMock{}, createIssue)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "reaction", options.UpdateMode)
+	assert.Equal(t, 1, calls, "remaining chunks must not be added to an existing issue")
+}
+
+func TestGetBodyFingerprint(t *testing.T) {
+	config := githubCreateIssueOptions{
+		Body:        strings.Repeat("a", 100),
+		ChunkSize:   100,
+		Fingerprint: "my-fingerprint",
+	}
+
+	chunks, err := getBody(&config, nil)
+
+	assert.NoError(t, err)
+	if assert.Len(t, chunks, 2) {
+		assert.Len(t, chunks[0]+piperGithub.FingerprintMarker(config.Fingerprint), 100)
+	}
+}
+
+func TestGetBodyInvalidChunkSize(t *testing.T) {
+	t.Run("chunk size not larger than the fingerprint marker", func(t *testing.T) {
+		config := githubCreateIssueOptions{Body: "body", ChunkSize: 43, Fingerprint: "my-fingerprint"}
+
+		_, err := getBody(&config, nil)
+
+		assert.EqualError(t, err, "chunkSize must be larger than 43, the size of the fingerprint marker")
+	})
+
+	t.Run("chunk size zero", func(t *testing.T) {
+		config := githubCreateIssueOptions{Body: "body"}
+
+		_, err := getBody(&config, nil)
+
+		assert.EqualError(t, err, "chunkSize must be larger than 0")
+	})
+}
+
+func TestGetBodyMaxBodyBytes(t *testing.T) {
+	t.Run("body is not split", func(t *testing.T) {
+		config := githubCreateIssueOptions{Body: strings.Repeat("a", 100), ChunkSize: 10, MaxBodyBytes: 50}
+
+		chunks, err := getBody(&config, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{config.Body}, chunks)
+	})
+
+	t.Run("exceeding the GitHub limit", func(t *testing.T) {
+		config := githubCreateIssueOptions{Body: "body", MaxBodyBytes: 70000}
+
+		_, err := getBody(&config, nil)
+
+		assert.EqualError(t, err, "maxBodyBytes must not exceed 65536 bytes, GitHub does not accept larger issue bodies")
+	})
+
+	t.Run("exceeding the GitHub limit including the fingerprint", func(t *testing.T) {
+		config := githubCreateIssueOptions{Body: "body", MaxBodyBytes: 65536, Fingerprint: "my-fingerprint"}
+
+		_, err := getBody(&config, nil)
+
+		assert.ErrorContains(t, err, "maxBodyBytes must not exceed 65493 bytes")
 	})
 }
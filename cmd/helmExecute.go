@@ -1,18 +1,58 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
 
+	"github.com/SAP/jenkins-library/pkg/config"
 	"github.com/SAP/jenkins-library/pkg/kubernetes"
 	"github.com/SAP/jenkins-library/pkg/log"
 	"github.com/SAP/jenkins-library/pkg/piperenv"
 	"github.com/SAP/jenkins-library/pkg/telemetry"
 	"github.com/SAP/jenkins-library/pkg/versioning"
+	"github.com/mitchellh/mapstructure"
 )
 
 func helmExecute(config helmExecuteOptions, telemetryData *telemetry.CustomData, commonPipelineEnvironment *helmExecuteCommonPipelineEnvironment) {
+	// keepFailedDeployments is deprecated, but still disables atomic during the transition
+	atomic := config.Atomic && !config.KeepFailedDeployments
+
+	readinessChecks := []kubernetes.ResourceRef{}
+	if err := mapstructure.Decode(config.ReadinessChecks, &readinessChecks); err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		log.Entry().WithError(err).Fatalf("invalid readinessChecks: %v", err)
+	}
+
+	charts := []kubernetes.HelmChart{}
+	if err := mapstructure.Decode(config.Charts, &charts); err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		log.Entry().WithError(err).Fatalf("invalid charts: %v", err)
+	}
+
+	publishTargets := []kubernetes.HelmPublishTarget{}
+	if err := mapstructure.Decode(config.PublishTargets, &publishTargets); err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		log.Entry().WithError(err).Fatalf("invalid publishTargets: %v", err)
+	}
+	for _, target := range publishTargets {
+		log.RegisterSecret(target.Password)
+	}
+
+	secretValues, err := resolveVaultValues(config.VaultValues)
+	if err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		log.Entry().WithError(err).Fatalf("failed to resolve vaultValues: %v", err)
+	}
+
 	helmConfig := kubernetes.HelmExecuteOptions{
 		AdditionalParameters:      config.AdditionalParameters,
 		ChartPath:                 config.ChartPath,
@@ -20,8 +60,11 @@ func helmExecute(config helmExecuteOptions, telemetryData *telemetry.CustomData,
 		Namespace:                 config.Namespace,
 		KubeContext:               config.KubeContext,
 		KeepFailedDeployments:     config.KeepFailedDeployments,
+		Atomic:                    &atomic,
 		KubeConfig:                config.KubeConfig,
 		HelmDeployWaitSeconds:     config.HelmDeployWaitSeconds,
+		HelmTimeout:               config.HelmTimeout,
+		WaitForJobs:               config.WaitForJobs,
 		DockerConfigJSON:          config.DockerConfigJSON,
 		AppVersion:                config.AppVersion,
 		Dependency:                config.Dependency,
@@ -42,6 +85,66 @@ func helmExecute(config helmExecuteOptions, telemetryData *telemetry.CustomData,
 		Version:                   config.Version,
 		PublishVersion:            config.Version,
 		RenderSubchartNotes:       config.RenderSubchartNotes,
+		CreateNamespace:           config.CreateNamespace,
+		KeepHistory:               config.KeepHistory,
+		PreflightCheck:            config.PreflightCheck,
+		HelmBinary:                config.HelmBinary,
+		ResultFile:                config.ResultFile,
+		Description:               config.Description,
+		DryRunOnly:                config.DryRunOnly,
+		ResetValues:               config.ResetValues,
+		ReuseValues:               config.ReuseValues,
+		StepTimeout:               config.StepTimeout,
+		DependencyLocalPath:       config.DependencyLocalPath,
+		LintWithSubcharts:         config.LintWithSubcharts,
+		IsolatedHelmHome:          config.IsolatedHelmHome,
+		HelmEnv:                   stringMap(config.HelmEnv),
+		ReadinessChecks:           readinessChecks,
+		ReadinessTimeout:          config.ReadinessTimeout,
+		PublishTargets:            publishTargets,
+		UploadHeaders:             stringMap(config.UploadHeaders),
+		PackageDestination:        config.PackageDestination,
+		CleanupSelector:           config.CleanupSelector,
+		CleanupKinds:              config.CleanupKinds,
+		CleanupOnFail:             config.CleanupOnFail,
+		SecretValues:              secretValues,
+		DebugCommands:             config.DebugCommands,
+		TruncateReleaseName:       config.TruncateReleaseName,
+		TakeOwnership:             config.TakeOwnership,
+		DeployRecordFile:          config.DeployRecordFile,
+		KubeToken:                 config.KubeToken,
+		KubeAPIServer:             config.KubeAPIServer,
+		KubeCACertificate:         config.KubeCACertificate,
+		HistoryMax:                config.HistoryMax,
+		ResumableUpload:           config.ResumableUpload,
+		DependencyUpdate:          config.DependencyUpdate,
+		WorkingDirectory:          config.WorkingDirectory,
+		KubeAPITimeout:            config.KubeAPITimeout,
+		SetJSONValues:             config.SetJSONValues,
+		SkipRepoAdd:               config.SkipRepoAdd,
+		NamespaceLabels:           stringMap(config.NamespaceLabels),
+		NamespaceAnnotations:      stringMap(config.NamespaceAnnotations),
+		RunTestsAfterDeploy:       config.RunTestsAfterDeploy,
+		TestResultFile:            config.TestResultFile,
+		BackupValues:              config.BackupValues,
+		ValuesBackupFile:          config.ValuesBackupFile,
+		ReleaseLabels:             stringMap(config.ReleaseLabels),
+		DiffReportFile:            config.DiffReportFile,
+		UpgradeRetries:            config.UpgradeRetries,
+		HTTPSProxy:                config.HttpsProxy,
+		HTTPProxy:                 config.HttpProxy,
+		NoProxy:                   config.NoProxy,
+		ProgressInterval:          config.ProgressInterval,
+		ValidateValuesSchema:      config.ValidateValuesSchema,
+		UseSecretsPlugin:          config.UseSecretsPlugin,
+		EncryptedHelmValues:       config.EncryptedHelmValues,
+		Cascade:                   config.Cascade,
+		UpgradeTimeout:            config.UpgradeTimeout,
+		InstallTimeout:            config.InstallTimeout,
+		UninstallTimeout:          config.UninstallTimeout,
+		TestTimeout:               config.TestTimeout,
+		DryRunMode:                config.DryRunMode,
+		SecretValuePatterns:       config.SecretValuePatterns,
 	}
 
 	utils := kubernetes.NewDeployUtilsBundle(helmConfig.CustomTLSCertificateLinks)
@@ -52,7 +155,7 @@ func helmExecute(config helmExecuteOptions, telemetryData *telemetry.CustomData,
 
 	buildDescriptorFile := ""
 	if helmConfig.ChartPath != "" {
-		buildDescriptorFile = filepath.Join(helmConfig.ChartPath, "Chart.yaml")
+		buildDescriptorFile = helmWorkingPath(config, filepath.Join(helmConfig.ChartPath, "Chart.yaml"))
 	}
 
 	artifact, err := versioning.GetArtifact("helm", buildDescriptorFile, &artifactOpts, utils)
@@ -64,10 +167,12 @@ func helmExecute(config helmExecuteOptions, telemetryData *telemetry.CustomData,
 		log.Entry().WithError(err).Fatalf("getting artifact coordinates failed: %v", err)
 	}
 
-	helmConfig.DeploymentName = artifactInfo.ArtifactID
-
-	if len(helmConfig.PublishVersion) == 0 {
-		helmConfig.PublishVersion = artifactInfo.Version
+	// name and version of the chart in chartPath are applied by the helm executor, the artifact is only used without chartPath
+	if len(helmConfig.ChartPath) == 0 {
+		helmConfig.DeploymentName = artifactInfo.ArtifactID
+		if len(helmConfig.PublishVersion) == 0 {
+			helmConfig.PublishVersion = artifactInfo.Version
+		}
 	}
 
 	err = parseAndRenderCPETemplate(config, GeneralConfig.EnvRootPath, utils)
@@ -75,22 +180,94 @@ func helmExecute(config helmExecuteOptions, telemetryData *telemetry.CustomData,
 		log.Entry().WithError(err).Fatalf("failed to parse/render template: %v", err)
 	}
 
-	helmExecutor := kubernetes.NewHelmExecutor(helmConfig, utils, GeneralConfig.Verbose, log.Writer())
+	helmConfig.AdditionalParameters, err = renderCPEAdditionalParameters(config, GeneralConfig.EnvRootPath)
+	if err != nil {
+		log.Entry().WithError(err).Fatalf("failed to render additional parameters: %v", err)
+	}
 
-	// error situations should stop execution through log.Entry().Fatal() call which leads to an os.Exit(1) in the end
-	if err := runHelmExecute(config, helmExecutor, commonPipelineEnvironment); err != nil {
+	helmConfig.Namespace, err = renderCPENamespace(config, GeneralConfig.EnvRootPath)
+	if err != nil {
+		log.Entry().WithError(err).Fatalf("failed to render namespace: %v", err)
+	}
+
+	helmConfig.AppVersion, err = renderCPEAppVersion(config, GeneralConfig.EnvRootPath)
+	if err != nil {
+		log.Entry().WithError(err).Fatalf("failed to render app version: %v", err)
+	}
+
+	if len(charts) > 0 {
+		if err := runHelmExecuteParallel(config, helmConfig, charts, func() kubernetes.DeployUtils {
+			return kubernetes.NewDeployUtilsBundle(helmConfig.CustomTLSCertificateLinks)
+		}); err != nil {
+			log.Entry().WithError(err).Fatalf("step execution failed: %v", err)
+		}
+		return
+	}
+
+	// terminate running helm calls when the step is aborted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	helmExecutor := kubernetes.NewHelmExecutorWithContext(ctx, helmConfig, utils, GeneralConfig.Verbose, log.Writer())
+	cleanup := func() {
+		if err := helmExecutor.Cleanup(); err != nil {
+			log.Entry().WithError(err).Warn("failed to clean up after helm execution")
+		}
+	}
+	// temporary files like the kubeconfig containing the kubeToken are also removed in case the step is terminated via log.Entry().Fatal()
+	log.DeferExitHandler(cleanup)
+
+	// errors are returned, so that temporary files are cleaned up before the step terminates
+	err = runHelmExecute(config, helmExecutor, commonPipelineEnvironment)
+	cleanup()
+	if err != nil {
 		log.Entry().WithError(err).Fatalf("step execution failed: %v", err)
 	}
 }
 
+// stringMap converts the values of a configured map, e.g. helmEnv, into their string representation
+func stringMap(values map[string]interface{}) map[string]string {
+	result := make(map[string]string, len(values))
+	for key, value := range values {
+		result[key] = fmt.Sprint(value)
+	}
+	return result
+}
+
+// resolveVaultValues resolves the values referencing Vault credential keys, which are exposed as environment variables
+// for the keys configured via vaultCredentialPath and vaultCredentialKeys
+func resolveVaultValues(vaultValues map[string]interface{}) (map[string]string, error) {
+	paths := make([]string, 0, len(vaultValues))
+	for path := range vaultValues {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	secretValues := make(map[string]string, len(vaultValues))
+	for _, path := range paths {
+		key := fmt.Sprint(vaultValues[path])
+		envVar := config.VaultCredentialEnvPrefixDefault + config.ConvertEnvVar(key)
+		value, ok := os.LookupEnv(envVar)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %q is not set (required by value '%v'), please add '%v' to vaultCredentialKeys", envVar, path, key)
+		}
+		secretValues[path] = value
+	}
+	return secretValues, nil
+}
+
 func runHelmExecute(config helmExecuteOptions, helmExecutor kubernetes.HelmExecutor, commonPipelineEnvironment *helmExecuteCommonPipelineEnvironment) error {
+	if config.KeepHistory && config.HelmCommand != "uninstall" {
+		log.Entry().Warn("parameter keepHistory is only considered for helm command 'uninstall'")
+	}
+
 	switch config.HelmCommand {
 	case "upgrade":
 		if err := helmExecutor.RunHelmUpgrade(); err != nil {
 			return fmt.Errorf("failed to execute upgrade: %v", err)
 		}
 	case "lint":
-		if err := helmExecutor.RunHelmLint(); err != nil {
+		if _, err := helmExecutor.RunHelmLint(); err != nil {
 			return fmt.Errorf("failed to execute helm lint: %v", err)
 		}
 	case "install":
@@ -109,6 +286,10 @@ func runHelmExecute(config helmExecuteOptions, helmExecutor kubernetes.HelmExecu
 		if err := helmExecutor.RunHelmDependency(); err != nil {
 			return fmt.Errorf("failed to execute helm dependency: %v", err)
 		}
+	case "diff":
+		if _, err := helmExecutor.RunHelmDiff(); err != nil {
+			return fmt.Errorf("failed to execute helm diff: %v", err)
+		}
 	case "publish":
 		targetURL, err := helmExecutor.RunHelmPublish()
 		if err != nil {
@@ -124,8 +305,28 @@ func runHelmExecute(config helmExecuteOptions, helmExecutor kubernetes.HelmExecu
 	return nil
 }
 
+// runHelmExecuteParallel deploys the chart of chartPath and the additional charts with at most maxParallel concurrent helm calls
+func runHelmExecuteParallel(config helmExecuteOptions, helmConfig kubernetes.HelmExecuteOptions, charts []kubernetes.HelmChart, newUtils func() kubernetes.DeployUtils) error {
+	var operation kubernetes.HelmOperation
+	switch config.HelmCommand {
+	case "upgrade":
+		operation = kubernetes.HelmExecutor.RunHelmUpgrade
+	case "install":
+		operation = kubernetes.HelmExecutor.RunHelmInstall
+	default:
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return fmt.Errorf("charts are only supported by the helm commands upgrade and install, not by '%v'", config.HelmCommand)
+	}
+
+	configs := []kubernetes.HelmExecuteOptions{helmConfig}
+	for _, chart := range charts {
+		configs = append(configs, helmConfig.WithChart(chart))
+	}
+	return kubernetes.RunHelmParallel(configs, newUtils, GeneralConfig.Verbose, log.Writer(), config.MaxParallel, operation)
+}
+
 func runHelmExecuteDefault(config helmExecuteOptions, helmExecutor kubernetes.HelmExecutor, commonPipelineEnvironment *helmExecuteCommonPipelineEnvironment) error {
-	if err := helmExecutor.RunHelmLint(); err != nil {
+	if _, err := helmExecutor.RunHelmLint(); err != nil {
 		return fmt.Errorf("failed to execute helm lint: %v", err)
 	}
 
@@ -146,16 +347,172 @@ func runHelmExecuteDefault(config helmExecuteOptions, helmExecutor kubernetes.He
 	return nil
 }
 
+// helmWorkingPath returns the path of a file passed to helm, which is relative to the configured workingDirectory
+func helmWorkingPath(config helmExecuteOptions, file string) string {
+	if len(config.WorkingDirectory) == 0 || filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(config.WorkingDirectory, file)
+}
+
+// renderCPEAdditionalParameters renders the additional parameters which contain references to the CPE, e.g. --set annotations.commit={{ git "commitId" }}
+// Parameters without a template are passed through unchanged.
+func renderCPEAdditionalParameters(config helmExecuteOptions, rootPath string) ([]string, error) {
+	if len(config.AdditionalParameters) == 0 {
+		return config.AdditionalParameters, nil
+	}
+
+	cpe := piperenv.CPEMap{}
+	err := cpe.LoadFromDisk(path.Join(rootPath, "commonPipelineEnvironment"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load values from commonPipelineEnvironment: %v", err)
+	}
+
+	params := make([]string, 0, len(config.AdditionalParameters))
+	for _, param := range config.AdditionalParameters {
+		rendered, err := renderCPEString(cpe, param, config)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, rendered)
+	}
+
+	return params, nil
+}
+
+var namespaceRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// renderCPENamespace renders a namespace which contains references to the CPE, e.g. app-{{ cpe "custom/environment" }}
+// The rendered namespace has to be a valid DNS-1123 label as required by Kubernetes.
+func renderCPENamespace(config helmExecuteOptions, rootPath string) (string, error) {
+	if !strings.Contains(config.Namespace, templateStartDelimiter(config)) {
+		return config.Namespace, nil
+	}
+
+	cpe := piperenv.CPEMap{}
+	if err := cpe.LoadFromDisk(path.Join(rootPath, "commonPipelineEnvironment")); err != nil {
+		return "", fmt.Errorf("failed to load values from commonPipelineEnvironment: %v", err)
+	}
+	namespace, err := renderCPEString(cpe, config.Namespace, config)
+	if err != nil {
+		return "", err
+	}
+
+	if len(namespace) == 0 {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return "", fmt.Errorf("namespace template '%v' rendered to an empty namespace", config.Namespace)
+	}
+	if len(namespace) > 63 || !namespaceRegex.MatchString(namespace) {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return "", fmt.Errorf("invalid namespace '%v' rendered from '%v': a namespace must consist of at most 63 lower case alphanumeric characters or '-', and must start and end with an alphanumeric character", namespace, config.Namespace)
+	}
+
+	log.Entry().Infof("Using namespace '%v' rendered from '%v'", namespace, config.Namespace)
+	return namespace, nil
+}
+
+// renderCPEAppVersion renders an app version which contains references to the CPE, e.g. {{ cpe "artifactVersion" }}
+func renderCPEAppVersion(config helmExecuteOptions, rootPath string) (string, error) {
+	if !strings.Contains(config.AppVersion, templateStartDelimiter(config)) {
+		return config.AppVersion, nil
+	}
+
+	cpe := piperenv.CPEMap{}
+	if err := cpe.LoadFromDisk(path.Join(rootPath, "commonPipelineEnvironment")); err != nil {
+		return "", fmt.Errorf("failed to load values from commonPipelineEnvironment: %v", err)
+	}
+	appVersion, err := renderCPEString(cpe, config.AppVersion, config)
+	if err != nil {
+		return "", err
+	}
+	appVersion = strings.TrimSpace(appVersion)
+
+	if len(appVersion) == 0 || appVersion == "<nil>" {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return "", fmt.Errorf("app version template '%v' rendered to an empty app version", config.AppVersion)
+	}
+
+	log.Entry().Infof("Using app version '%v' rendered from '%v'", appVersion, config.AppVersion)
+	return appVersion, nil
+}
+
+// renderCPEString renders value in case it contains a template, otherwise value is returned unchanged
+func renderCPEString(cpe piperenv.CPEMap, value string, config helmExecuteOptions) (string, error) {
+	if !strings.Contains(value, templateStartDelimiter(config)) {
+		return value, nil
+	}
+	generated, err := cpe.ParseTemplateWithDelimiter(value, config.TemplateStartDelimiter, config.TemplateEndDelimiter)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %v", err)
+	}
+	return generated.String(), nil
+}
+
+func templateStartDelimiter(config helmExecuteOptions) string {
+	if len(config.TemplateStartDelimiter) == 0 {
+		return piperenv.DEFAULT_START_DELIMITER
+	}
+	return config.TemplateStartDelimiter
+}
+
+func templateEndDelimiter(config helmExecuteOptions) string {
+	if len(config.TemplateEndDelimiter) == 0 {
+		return piperenv.DEFAULT_END_DELIMITER
+	}
+	return config.TemplateEndDelimiter
+}
+
+var envReferenceRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandTemplateEnv replaces references to environment variables in the form ${NAME} outside of template actions,
+// so that the template actions, e.g. {{ cpe "artifactVersion" }}, are left to the template pass.
+// References to undefined variables are kept.
+func expandTemplateEnv(content, startDelimiter, endDelimiter string) string {
+	expanded := strings.Builder{}
+	for len(content) > 0 {
+		start := strings.Index(content, startDelimiter)
+		if start < 0 {
+			start = len(content)
+		}
+		expanded.WriteString(envReferenceRegexp.ReplaceAllStringFunc(content[:start], func(reference string) string {
+			name := envReferenceRegexp.FindStringSubmatch(reference)[1]
+			if value, ok := os.LookupEnv(name); ok {
+				return value
+			}
+			log.Entry().Warnf("Environment variable %v is not defined, keeping %v", name, reference)
+			return reference
+		}))
+		content = content[start:]
+		if len(content) == 0 {
+			break
+		}
+		end := strings.Index(content, endDelimiter)
+		if end < 0 {
+			// an unclosed action is reported by the template pass
+			expanded.WriteString(content)
+			break
+		}
+		expanded.WriteString(content[:end+len(endDelimiter)])
+		content = content[end+len(endDelimiter):]
+	}
+	return expanded.String()
+}
+
 // parseAndRenderCPETemplate allows to parse and render a template which contains references to the CPE
 func parseAndRenderCPETemplate(config helmExecuteOptions, rootPath string, utils kubernetes.DeployUtils) error {
+	fileMode, err := renderFileMode(config.RenderFileMode)
+	if err != nil {
+		return err
+	}
+
 	cpe := piperenv.CPEMap{}
-	err := cpe.LoadFromDisk(path.Join(rootPath, "commonPipelineEnvironment"))
+	err = cpe.LoadFromDisk(path.Join(rootPath, "commonPipelineEnvironment"))
 	if err != nil {
 		return fmt.Errorf("failed to load values from commonPipelineEnvironment: %v", err)
 	}
 
 	valueFiles := []string{}
-	defaultValueFile := fmt.Sprintf("%s/%s", config.ChartPath, "values.yaml")
+	defaultValueFile := helmWorkingPath(config, fmt.Sprintf("%s/%s", config.ChartPath, "values.yaml"))
 	defaultValueFileExists, err := utils.FileExists(defaultValueFile)
 	if err != nil {
 		return err
@@ -171,15 +528,24 @@ func parseAndRenderCPETemplate(config helmExecuteOptions, rootPath string, utils
 	valueFiles = append(valueFiles, config.HelmValues...)
 
 	for _, valueFile := range valueFiles {
-		cpeTemplate, err := utils.FileRead(valueFile)
+		if strings.HasPrefix(valueFile, "http://") || strings.HasPrefix(valueFile, "https://") {
+			log.Entry().Debugf("Skipping templating of remote values file %v", valueFile)
+			continue
+		}
+		valueFile = helmWorkingPath(config, valueFile)
+		content, err := utils.FileRead(valueFile)
 		if err != nil {
 			return fmt.Errorf("failed to read file: %v", err)
 		}
-		generated, err := cpe.ParseTemplateWithDelimiter(string(cpeTemplate), config.TemplateStartDelimiter, config.TemplateEndDelimiter)
+		cpeTemplate := string(content)
+		if config.ExpandEnv {
+			cpeTemplate = expandTemplateEnv(cpeTemplate, templateStartDelimiter(config), templateEndDelimiter(config))
+		}
+		generated, err := cpe.ParseTemplateWithDelimiter(cpeTemplate, config.TemplateStartDelimiter, config.TemplateEndDelimiter)
 		if err != nil {
 			return fmt.Errorf("failed to parse template: %v", err)
 		}
-		err = utils.FileWrite(valueFile, generated.Bytes(), 0700)
+		err = utils.FileWrite(valueFile, generated.Bytes(), fileMode)
 		if err != nil {
 			return fmt.Errorf("failed to update file: %v", err)
 		}
@@ -187,3 +553,21 @@ func parseAndRenderCPETemplate(config helmExecuteOptions, rootPath string, utils
 
 	return nil
 }
+
+// renderFileMode parses the octal file mode used for writing rendered value files, e.g. 0644
+func renderFileMode(mode string) (os.FileMode, error) {
+	if len(mode) == 0 {
+		return 0700, nil
+	}
+	parsed, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return 0, fmt.Errorf("invalid renderFileMode '%v', please provide an octal file mode like 0644: %v", mode, err)
+	}
+	// the file needs to be readable by the owner for helm and must not carry special bits like setuid
+	if parsed > 0777 || parsed&0400 == 0 {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return 0, fmt.Errorf("invalid renderFileMode '%v', the mode must be between 0400 and 0777 and readable by the owner", mode)
+	}
+	return os.FileMode(parsed), nil
+}
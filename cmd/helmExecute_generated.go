@@ -18,36 +18,103 @@ import (
 )
 
 type helmExecuteOptions struct {
-	AdditionalParameters      []string `json:"additionalParameters,omitempty"`
-	ChartPath                 string   `json:"chartPath,omitempty"`
-	TargetRepositoryURL       string   `json:"targetRepositoryURL,omitempty"`
-	TargetRepositoryName      string   `json:"targetRepositoryName,omitempty"`
-	TargetRepositoryUser      string   `json:"targetRepositoryUser,omitempty"`
-	TargetRepositoryPassword  string   `json:"targetRepositoryPassword,omitempty"`
-	SourceRepositoryURL       string   `json:"sourceRepositoryURL,omitempty"`
-	SourceRepositoryName      string   `json:"sourceRepositoryName,omitempty"`
-	SourceRepositoryUser      string   `json:"sourceRepositoryUser,omitempty"`
-	SourceRepositoryPassword  string   `json:"sourceRepositoryPassword,omitempty"`
-	HelmDeployWaitSeconds     int      `json:"helmDeployWaitSeconds,omitempty"`
-	HelmValues                []string `json:"helmValues,omitempty"`
-	Image                     string   `json:"image,omitempty"`
-	KeepFailedDeployments     bool     `json:"keepFailedDeployments,omitempty"`
-	KubeConfig                string   `json:"kubeConfig,omitempty"`
-	KubeContext               string   `json:"kubeContext,omitempty"`
-	Namespace                 string   `json:"namespace,omitempty"`
-	DockerConfigJSON          string   `json:"dockerConfigJSON,omitempty"`
-	HelmCommand               string   `json:"helmCommand,omitempty" validate:"possible-values=upgrade lint install test uninstall dependency publish"`
-	AppVersion                string   `json:"appVersion,omitempty"`
-	Dependency                string   `json:"dependency,omitempty" validate:"possible-values=build list update"`
-	PackageDependencyUpdate   bool     `json:"packageDependencyUpdate,omitempty"`
-	DumpLogs                  bool     `json:"dumpLogs,omitempty"`
-	FilterTest                string   `json:"filterTest,omitempty"`
-	CustomTLSCertificateLinks []string `json:"customTlsCertificateLinks,omitempty"`
-	Publish                   bool     `json:"publish,omitempty"`
-	Version                   string   `json:"version,omitempty"`
-	RenderSubchartNotes       bool     `json:"renderSubchartNotes,omitempty"`
-	TemplateStartDelimiter    string   `json:"templateStartDelimiter,omitempty"`
-	TemplateEndDelimiter      string   `json:"templateEndDelimiter,omitempty"`
+	AdditionalParameters      []string                 `json:"additionalParameters,omitempty"`
+	ChartPath                 string                   `json:"chartPath,omitempty"`
+	WorkingDirectory          string                   `json:"workingDirectory,omitempty"`
+	TargetRepositoryURL       string                   `json:"targetRepositoryURL,omitempty"`
+	TargetRepositoryName      string                   `json:"targetRepositoryName,omitempty"`
+	TargetRepositoryUser      string                   `json:"targetRepositoryUser,omitempty"`
+	TargetRepositoryPassword  string                   `json:"targetRepositoryPassword,omitempty"`
+	PublishTargets            []map[string]interface{} `json:"publishTargets,omitempty"`
+	PackageDestination        string                   `json:"packageDestination,omitempty"`
+	ResumableUpload           bool                     `json:"resumableUpload,omitempty"`
+	UploadHeaders             map[string]interface{}   `json:"uploadHeaders,omitempty"`
+	SourceRepositoryURL       string                   `json:"sourceRepositoryURL,omitempty"`
+	SourceRepositoryName      string                   `json:"sourceRepositoryName,omitempty"`
+	SourceRepositoryUser      string                   `json:"sourceRepositoryUser,omitempty"`
+	SourceRepositoryPassword  string                   `json:"sourceRepositoryPassword,omitempty"`
+	HelmDeployWaitSeconds     int                      `json:"helmDeployWaitSeconds,omitempty"`
+	HelmTimeout               string                   `json:"helmTimeout,omitempty"`
+	UpgradeTimeout            string                   `json:"upgradeTimeout,omitempty"`
+	InstallTimeout            string                   `json:"installTimeout,omitempty"`
+	UninstallTimeout          string                   `json:"uninstallTimeout,omitempty"`
+	TestTimeout               string                   `json:"testTimeout,omitempty"`
+	KubeAPITimeout            string                   `json:"kubeAPITimeout,omitempty"`
+	WaitForJobs               bool                     `json:"waitForJobs,omitempty"`
+	HelmValues                []string                 `json:"helmValues,omitempty"`
+	SetJSONValues             []string                 `json:"setJSONValues,omitempty"`
+	SkipRepoAdd               bool                     `json:"skipRepoAdd,omitempty"`
+	Image                     string                   `json:"image,omitempty"`
+	Atomic                    bool                     `json:"atomic,omitempty"`
+	CleanupOnFail             bool                     `json:"cleanupOnFail,omitempty"`
+	KeepFailedDeployments     bool                     `json:"keepFailedDeployments,omitempty"`
+	KubeConfig                string                   `json:"kubeConfig,omitempty"`
+	KubeContext               string                   `json:"kubeContext,omitempty"`
+	KubeToken                 string                   `json:"kubeToken,omitempty"`
+	KubeAPIServer             string                   `json:"kubeAPIServer,omitempty"`
+	KubeCACertificate         string                   `json:"kubeCACertificate,omitempty"`
+	Namespace                 string                   `json:"namespace,omitempty"`
+	DockerConfigJSON          string                   `json:"dockerConfigJSON,omitempty"`
+	HelmCommand               string                   `json:"helmCommand,omitempty" validate:"possible-values=upgrade lint install test uninstall dependency publish diff"`
+	AppVersion                string                   `json:"appVersion,omitempty"`
+	Dependency                string                   `json:"dependency,omitempty" validate:"possible-values=build list update"`
+	DependencyLocalPath       string                   `json:"dependencyLocalPath,omitempty"`
+	PackageDependencyUpdate   bool                     `json:"packageDependencyUpdate,omitempty"`
+	DependencyUpdate          bool                     `json:"dependencyUpdate,omitempty"`
+	DumpLogs                  bool                     `json:"dumpLogs,omitempty"`
+	RunTestsAfterDeploy       bool                     `json:"runTestsAfterDeploy,omitempty"`
+	FilterTest                string                   `json:"filterTest,omitempty"`
+	TestResultFile            string                   `json:"testResultFile,omitempty"`
+	CustomTLSCertificateLinks []string                 `json:"customTlsCertificateLinks,omitempty"`
+	Publish                   bool                     `json:"publish,omitempty"`
+	Version                   string                   `json:"version,omitempty"`
+	RenderSubchartNotes       bool                     `json:"renderSubchartNotes,omitempty"`
+	HelmBinary                string                   `json:"helmBinary,omitempty"`
+	StepTimeout               string                   `json:"stepTimeout,omitempty"`
+	DryRunOnly                bool                     `json:"dryRunOnly,omitempty"`
+	DryRunMode                string                   `json:"dryRunMode,omitempty" validate:"possible-values=client server"`
+	PreflightCheck            bool                     `json:"preflightCheck,omitempty"`
+	CreateNamespace           bool                     `json:"createNamespace,omitempty"`
+	NamespaceLabels           map[string]interface{}   `json:"namespaceLabels,omitempty"`
+	NamespaceAnnotations      map[string]interface{}   `json:"namespaceAnnotations,omitempty"`
+	Description               string                   `json:"description,omitempty"`
+	ResetValues               bool                     `json:"resetValues,omitempty"`
+	ReuseValues               bool                     `json:"reuseValues,omitempty"`
+	LintWithSubcharts         bool                     `json:"lintWithSubcharts,omitempty"`
+	IsolatedHelmHome          bool                     `json:"isolatedHelmHome,omitempty"`
+	VaultValues               map[string]interface{}   `json:"vaultValues,omitempty"`
+	HelmEnv                   map[string]interface{}   `json:"helmEnv,omitempty"`
+	ReadinessChecks           []map[string]interface{} `json:"readinessChecks,omitempty"`
+	ReadinessTimeout          string                   `json:"readinessTimeout,omitempty"`
+	CleanupSelector           string                   `json:"cleanupSelector,omitempty"`
+	CleanupKinds              []string                 `json:"cleanupKinds,omitempty"`
+	DebugCommands             []string                 `json:"debugCommands,omitempty"`
+	TruncateReleaseName       bool                     `json:"truncateReleaseName,omitempty"`
+	TakeOwnership             bool                     `json:"takeOwnership,omitempty"`
+	ReleaseLabels             map[string]interface{}   `json:"releaseLabels,omitempty"`
+	HistoryMax                int                      `json:"historyMax,omitempty"`
+	KeepHistory               bool                     `json:"keepHistory,omitempty"`
+	Cascade                   string                   `json:"cascade,omitempty" validate:"possible-values=background foreground orphan"`
+	ResultFile                string                   `json:"resultFile,omitempty"`
+	BackupValues              bool                     `json:"backupValues,omitempty"`
+	ValuesBackupFile          string                   `json:"valuesBackupFile,omitempty"`
+	DeployRecordFile          string                   `json:"deployRecordFile,omitempty"`
+	RenderFileMode            string                   `json:"renderFileMode,omitempty"`
+	DiffReportFile            string                   `json:"diffReportFile,omitempty"`
+	UpgradeRetries            int                      `json:"upgradeRetries,omitempty"`
+	HttpsProxy                string                   `json:"httpsProxy,omitempty"`
+	HttpProxy                 string                   `json:"httpProxy,omitempty"`
+	NoProxy                   string                   `json:"noProxy,omitempty"`
+	ProgressInterval          string                   `json:"progressInterval,omitempty"`
+	ValidateValuesSchema      bool                     `json:"validateValuesSchema,omitempty"`
+	UseSecretsPlugin          bool                     `json:"useSecretsPlugin,omitempty"`
+	EncryptedHelmValues       []string                 `json:"encryptedHelmValues,omitempty"`
+	SecretValuePatterns       []string                 `json:"secretValuePatterns,omitempty"`
+	Charts                    []map[string]interface{} `json:"charts,omitempty"`
+	MaxParallel               int                      `json:"maxParallel,omitempty"`
+	ExpandEnv                 bool                     `json:"expandEnv,omitempty"`
+	TemplateStartDelimiter    string                   `json:"templateStartDelimiter,omitempty"`
+	TemplateEndDelimiter      string                   `json:"templateEndDelimiter,omitempty"`
 }
 
 type helmExecuteCommonPipelineEnvironment struct {
@@ -136,6 +203,7 @@ Note: piper supports only helm3 version, since helm2 is deprecated.`,
 			log.RegisterSecret(stepConfig.SourceRepositoryUser)
 			log.RegisterSecret(stepConfig.SourceRepositoryPassword)
 			log.RegisterSecret(stepConfig.KubeConfig)
+			log.RegisterSecret(stepConfig.KubeToken)
 			log.RegisterSecret(stepConfig.DockerConfigJSON)
 
 			if len(GeneralConfig.HookConfig.SentryConfig.Dsn) > 0 {
@@ -200,38 +268,103 @@ Note: piper supports only helm3 version, since helm2 is deprecated.`,
 }
 
 func addHelmExecuteFlags(cmd *cobra.Command, stepConfig *helmExecuteOptions) {
-	cmd.Flags().StringSliceVar(&stepConfig.AdditionalParameters, "additionalParameters", []string{}, "Defines additional parameters for Helm like  \"helm install [NAME] [CHART] [flags]\".")
+	cmd.Flags().StringSliceVar(&stepConfig.AdditionalParameters, "additionalParameters", []string{}, "Defines additional parameters for Helm like  \"helm install [NAME] [CHART] [flags]\".\nParameters may contain references to the commonPipelineEnvironment using the same template syntax as the values files, e.g. `--set annotations.commit={{ git \"commitId\" }}`.\nValue overrides (`--set`, `--set-string`, `--set-file`, `--set-json`) are also passed to `lint`, so that the chart is linted with the values used for the deployment.")
 	cmd.Flags().StringVar(&stepConfig.ChartPath, "chartPath", os.Getenv("PIPER_chartPath"), "Defines the chart path for helm. chartPath is mandatory for install/upgrade/publish commands.")
+	cmd.Flags().StringVar(&stepConfig.WorkingDirectory, "workingDirectory", os.Getenv("PIPER_workingDirectory"), "Directory in which helm is executed, e.g. the directory of the charts in a monorepo.\n`chartPath`, `helmValues`, `packageDestination` and `dependencyLocalPath` are relative to this directory.\n")
 	cmd.Flags().StringVar(&stepConfig.TargetRepositoryURL, "targetRepositoryURL", os.Getenv("PIPER_targetRepositoryURL"), "URL of the target repository where the compiled helm .tgz archive shall be uploaded - typically provided by the CI/CD environment.")
 	cmd.Flags().StringVar(&stepConfig.TargetRepositoryName, "targetRepositoryName", os.Getenv("PIPER_targetRepositoryName"), "set the chart repository. The value is required for install/upgrade/uninstall commands.")
 	cmd.Flags().StringVar(&stepConfig.TargetRepositoryUser, "targetRepositoryUser", os.Getenv("PIPER_targetRepositoryUser"), "Username for the chart repository where the compiled helm .tgz archive shall be uploaded - typically provided by the CI/CD environment.")
 	cmd.Flags().StringVar(&stepConfig.TargetRepositoryPassword, "targetRepositoryPassword", os.Getenv("PIPER_targetRepositoryPassword"), "Password for the target repository where the compiled helm .tgz archive shall be uploaded - typically provided by the CI/CD environment.")
+
+	cmd.Flags().StringVar(&stepConfig.PackageDestination, "packageDestination", os.Getenv("PIPER_packageDestination"), "Directory into which the chart is packaged (`--destination` of `helm package`) and from which it is published. By default the chart archive is written into the current working directory.")
+	cmd.Flags().BoolVar(&stepConfig.ResumableUpload, "resumableUpload", false, "Continues an interrupted upload of the chart archive during `publish` instead of uploading the complete archive again.\nResuming requires the repository to support byte ranges (`Accept-Ranges: bytes`), otherwise the complete archive is uploaded again.\n")
+
 	cmd.Flags().StringVar(&stepConfig.SourceRepositoryURL, "sourceRepositoryURL", os.Getenv("PIPER_sourceRepositoryURL"), "URL of the source repository where the dependencies can be downloaded.")
 	cmd.Flags().StringVar(&stepConfig.SourceRepositoryName, "sourceRepositoryName", os.Getenv("PIPER_sourceRepositoryName"), "Set the name of the chart repository. The value might be required for fetching dependencies.")
 	cmd.Flags().StringVar(&stepConfig.SourceRepositoryUser, "sourceRepositoryUser", os.Getenv("PIPER_sourceRepositoryUser"), "Username for the chart repository for fetching the dependencies.")
 	cmd.Flags().StringVar(&stepConfig.SourceRepositoryPassword, "sourceRepositoryPassword", os.Getenv("PIPER_sourceRepositoryPassword"), "Password for the chart repository for fetching the dependencies.")
 	cmd.Flags().IntVar(&stepConfig.HelmDeployWaitSeconds, "helmDeployWaitSeconds", 300, "Number of seconds before helm deploy returns.")
+	cmd.Flags().StringVar(&stepConfig.HelmTimeout, "helmTimeout", os.Getenv("PIPER_helmTimeout"), "Time to wait for any individual Kubernetes operation as duration (e.g. `10m`, `1h`). Takes precedence over `helmDeployWaitSeconds`.")
+	cmd.Flags().StringVar(&stepConfig.UpgradeTimeout, "upgradeTimeout", os.Getenv("PIPER_upgradeTimeout"), "Timeout of `upgrade` as duration (e.g. `15m`). Takes precedence over `helmTimeout` and `helmDeployWaitSeconds`, which apply if not set.")
+	cmd.Flags().StringVar(&stepConfig.InstallTimeout, "installTimeout", os.Getenv("PIPER_installTimeout"), "Timeout of `install` as duration (e.g. `15m`). Takes precedence over `helmTimeout` and `helmDeployWaitSeconds`, which apply if not set.")
+	cmd.Flags().StringVar(&stepConfig.UninstallTimeout, "uninstallTimeout", os.Getenv("PIPER_uninstallTimeout"), "Timeout of `uninstall` as duration (e.g. `5m`). Takes precedence over `helmTimeout` and `helmDeployWaitSeconds`, which apply if not set.\nIf set, `uninstall` waits for the deletion of the resources also if `helmDeployWaitSeconds` is 0.\n")
+	cmd.Flags().StringVar(&stepConfig.TestTimeout, "testTimeout", os.Getenv("PIPER_testTimeout"), "Timeout of `test` and of the tests run by `runTestsAfterDeploy` as duration (e.g. `2m`), so that a hanging test fails fast.\nIn contrast to the other commands, `test` does not fall back to `helmTimeout` and `helmDeployWaitSeconds` but uses helm's default (5 minutes) if not set.\n")
+	cmd.Flags().StringVar(&stepConfig.KubeAPITimeout, "kubeAPITimeout", os.Getenv("PIPER_kubeAPITimeout"), "Timeout for requests to the Kubernetes API server as duration (e.g. `30s`), so that a slow or unreachable API server fails fast.\nIn contrast to `helmTimeout` and `helmDeployWaitSeconds`, which limit the wait for the rollout of a release, this timeout bounds the cluster connectivity check (`preflightCheck`) and the requests of `kubectl` (`--request-timeout`).\n")
+	cmd.Flags().BoolVar(&stepConfig.WaitForJobs, "waitForJobs", false, "Wait until all Jobs have been completed before marking the release as successful (used by `upgrade` and `install`).")
 	cmd.Flags().StringSliceVar(&stepConfig.HelmValues, "helmValues", []string{}, "List of helm values as YAML file reference or URL (as per helm parameter description for `-f` / `--values`)")
+	cmd.Flags().StringSliceVar(&stepConfig.SetJSONValues, "setJSONValues", []string{}, "List of structured values passed to `upgrade` and `install` via helm's `--set-json`, e.g. `['podAnnotations={\"team\":\"core\"}']`.\nEvery entry must have the form `key=<json>`. Requires helm 3.10 or newer.\n")
+	cmd.Flags().BoolVar(&stepConfig.SkipRepoAdd, "skipRepoAdd", false, "Skips `helm repo add` for the target repository, e.g. if the repository has already been added to helm before.\n`targetRepositoryName` is then used as is. Local charts (`chartPath`) and `oci://` references never add a repository.\n")
 	cmd.Flags().StringVar(&stepConfig.Image, "image", os.Getenv("PIPER_image"), "Full name of the image to be deployed.")
+	cmd.Flags().BoolVar(&stepConfig.Atomic, "atomic", true, "If set, a failed `upgrade` or `install` is rolled back (helm flag `--atomic`).")
+	cmd.Flags().BoolVar(&stepConfig.CleanupOnFail, "cleanupOnFail", false, "If set, resources which have been created by a failed `upgrade` are removed (helm flag `--cleanup-on-fail`), while the release itself is kept. Unlike `atomic` the release is not rolled back, hence both cannot be combined, i.e. `atomic` has to be set to `false`. `helm install` does not support this flag.")
 	cmd.Flags().BoolVar(&stepConfig.KeepFailedDeployments, "keepFailedDeployments", false, "Defines whether a failed deployment will be purged")
 	cmd.Flags().StringVar(&stepConfig.KubeConfig, "kubeConfig", os.Getenv("PIPER_kubeConfig"), "Defines the path to the \"kubeconfig\" file.")
 	cmd.Flags().StringVar(&stepConfig.KubeContext, "kubeContext", os.Getenv("PIPER_kubeContext"), "Defines the context to use from the \"kubeconfig\" file.")
-	cmd.Flags().StringVar(&stepConfig.Namespace, "namespace", `default`, "Defines the target Kubernetes namespace for the deployment.")
+	cmd.Flags().StringVar(&stepConfig.KubeToken, "kubeToken", os.Getenv("PIPER_kubeToken"), "Token (e.g. of a service account) used to authenticate against `kubeAPIServer` instead of a `kubeConfig` file.\nA temporary kubeconfig is assembled from `kubeToken`, `kubeAPIServer` and `kubeCACertificate` and removed after the step. `kubeToken` takes precedence over `kubeConfig`.\n")
+	cmd.Flags().StringVar(&stepConfig.KubeAPIServer, "kubeAPIServer", os.Getenv("PIPER_kubeAPIServer"), "URL of the Kubernetes API server, e.g. `https://api.my.cluster:6443`. Mandatory when using `kubeToken`.")
+	cmd.Flags().StringVar(&stepConfig.KubeCACertificate, "kubeCACertificate", os.Getenv("PIPER_kubeCACertificate"), "PEM encoded CA certificate of the Kubernetes API server used with `kubeToken`. If not set, the system certificates are used.")
+	cmd.Flags().StringVar(&stepConfig.Namespace, "namespace", `default`, "Defines the target Kubernetes namespace for the deployment.\nThe namespace may contain references to the commonPipelineEnvironment using the same template syntax as the values files, e.g. `app-{{ cpe \"custom/environment\" }}`.\nThe rendered namespace must be a valid DNS-1123 label.")
 	cmd.Flags().StringVar(&stepConfig.DockerConfigJSON, "dockerConfigJSON", os.Getenv("PIPER_dockerConfigJSON"), "Path to the file `.docker/config.json` - this is typically provided by your CI/CD system. You can find more details about the Docker credentials in the [Docker documentation](https://docs.docker.com/engine/reference/commandline/login/).")
-	cmd.Flags().StringVar(&stepConfig.HelmCommand, "helmCommand", os.Getenv("PIPER_helmCommand"), "Helm: defines the command `upgrade`, `lint`, `install`, `test`, `uninstall`, `dependency`, `publish`.")
+	cmd.Flags().StringVar(&stepConfig.HelmCommand, "helmCommand", os.Getenv("PIPER_helmCommand"), "Helm: defines the command `upgrade`, `lint`, `install`, `test`, `uninstall`, `dependency`, `publish`, `diff`.\n`diff` shows the changes an upgrade would apply and requires the [helm-diff plugin](https://github.com/databus23/helm-diff).")
 	cmd.Flags().StringVar(&stepConfig.AppVersion, "appVersion", os.Getenv("PIPER_appVersion"), "set the appVersion on the chart to this version")
 	cmd.Flags().StringVar(&stepConfig.Dependency, "dependency", os.Getenv("PIPER_dependency"), "manage a chart's dependencies")
+	cmd.Flags().StringVar(&stepConfig.DependencyLocalPath, "dependencyLocalPath", os.Getenv("PIPER_dependencyLocalPath"), "Path to a directory containing vendored dependency chart archives for offline (air-gapped) builds (only used by `dependency`).\nThe directory is expected to contain the packaged dependencies as listed in `Chart.lock`, e.g. `<dependencyLocalPath>/common-1.2.3.tgz`.\nThe archives are copied into the `charts/` directory of the chart and `helm dependency build --skip-refresh` is used instead of `helm dependency update`.\nNo chart repositories are added in this mode.")
 	cmd.Flags().BoolVar(&stepConfig.PackageDependencyUpdate, "packageDependencyUpdate", false, "update dependencies from \"Chart.yaml\" to dir \"charts/\" before packaging")
-	cmd.Flags().BoolVar(&stepConfig.DumpLogs, "dumpLogs", false, "dump the logs from test pods (this runs after all tests are complete, but before any cleanup)")
+	cmd.Flags().BoolVar(&stepConfig.DependencyUpdate, "dependencyUpdate", false, "Updates the dependencies of a local chart before `upgrade` and `install` (`--dependency-update`), so that a stale `charts/` directory does not deploy outdated subcharts.\nFor helm versions before 3.8 the dependencies are updated via `helm dependency update` before `upgrade`.\n")
+	cmd.Flags().BoolVar(&stepConfig.DumpLogs, "dumpLogs", false, "dump the logs from test pods (this runs after all tests are complete, but before any cleanup). In case of test failures the logs are always dumped.")
+	cmd.Flags().BoolVar(&stepConfig.RunTestsAfterDeploy, "runTestsAfterDeploy", false, "Runs the tests of the release (`helm test`) after a successful `upgrade` or `install`, `filterTest` and `dumpLogs` are considered.\nIf the tests fail and the deployment is atomic (see `atomic`), the upgrade is rolled back to the previous revision and the install is uninstalled.\n")
 	cmd.Flags().StringVar(&stepConfig.FilterTest, "filterTest", os.Getenv("PIPER_filterTest"), "specify tests by attribute (currently `name`) using attribute=value syntax or `!attribute=value` to exclude a test (can specify multiple or separate values with commas `name=test1,name=test2`)")
+	cmd.Flags().StringVar(&stepConfig.TestResultFile, "testResultFile", os.Getenv("PIPER_testResultFile"), "Path of a JUnit XML file into which the results of the test pods of `helm test` are written, e.g. to publish them as test results of the pipeline.\nEach test pod is a test case, a failing `helm test` without failed test pods (e.g. a timeout) is reported as failed test case `helm test`.\n")
 	cmd.Flags().StringSliceVar(&stepConfig.CustomTLSCertificateLinks, "customTlsCertificateLinks", []string{}, "List of download links to custom TLS certificates. This is required to ensure trusted connections to instances with repositories (like nexus) when publish flag is set to true.")
 	cmd.Flags().BoolVar(&stepConfig.Publish, "publish", false, "Configures helm to run the deploy command to publish artifacts to a repository.")
 	cmd.Flags().StringVar(&stepConfig.Version, "version", os.Getenv("PIPER_version"), "Defines the artifact version to use from helm package/publish commands.")
-	cmd.Flags().BoolVar(&stepConfig.RenderSubchartNotes, "renderSubchartNotes", true, "If set, render subchart notes along with the parent.")
+	cmd.Flags().BoolVar(&stepConfig.RenderSubchartNotes, "renderSubchartNotes", true, "If set, render subchart notes along with the parent. The notes are contained in the `resultFile`.")
+	cmd.Flags().StringVar(&stepConfig.HelmBinary, "helmBinary", `helm`, "Defines the helm executable, either a name available on the `PATH` (e.g. `helm3`) or a path to the binary (e.g. `/opt/helm/helm`).")
+	cmd.Flags().StringVar(&stepConfig.StepTimeout, "stepTimeout", os.Getenv("PIPER_stepTimeout"), "Overall timeout for all helm calls of the step as duration (e.g. `30m`). Once exceeded, the running helm process is terminated.\nIn contrast to `helmTimeout`, which is passed to helm and only covers waiting for the Kubernetes resources, this also covers hanging cluster connections.")
+	cmd.Flags().BoolVar(&stepConfig.DryRunOnly, "dryRunOnly", false, "If set, the cluster is never modified or contacted. `upgrade` and `install` render the release locally via `helm template`,\n`uninstall` and `test` are skipped. `lint`, `dependency` and adding chart repositories are still performed.")
+	cmd.Flags().StringVar(&stepConfig.DryRunMode, "dryRunMode", `client`, "Mode of the dry-run which precedes `install` in verbose mode. With `server`, `upgrade` and `install` are always validated via `--dry-run=server` before the release is applied,\ni.e. rejections by admission webhooks (e.g. policy engines) fail the step before the cluster is modified. Requires helm 3.13 or newer.\nThe dry-run of `uninstall` in verbose mode is always a client side dry-run since helm does not support a server side dry-run for `uninstall`.")
+	cmd.Flags().BoolVar(&stepConfig.PreflightCheck, "preflightCheck", false, "If set, the connectivity to the cluster is verified before running the helm command in order to fail fast with a clear message.")
+	cmd.Flags().BoolVar(&stepConfig.CreateNamespace, "createNamespace", true, "Create the release namespace if not present (used by `upgrade`, `install` always creates the namespace).")
+
+	cmd.Flags().StringVar(&stepConfig.Description, "description", os.Getenv("PIPER_description"), "Adds a custom description to the release (used by `upgrade` and `install`), e.g. the URL of the pipeline run.")
+	cmd.Flags().BoolVar(&stepConfig.ResetValues, "resetValues", false, "When upgrading, reset the values to the ones built into the chart (only used by `upgrade`). Must not be combined with `reuseValues`.")
+	cmd.Flags().BoolVar(&stepConfig.ReuseValues, "reuseValues", false, "When upgrading, reuse the values of the last release and merge in the configured values (only used by `upgrade`). Must not be combined with `resetValues`.")
+	cmd.Flags().BoolVar(&stepConfig.LintWithSubcharts, "lintWithSubcharts", false, "Lint also the dependent charts (only used by `lint`), e.g. to validate the values against the `values.schema.json` of subcharts.")
+	cmd.Flags().BoolVar(&stepConfig.IsolatedHelmHome, "isolatedHelmHome", false, "Use temporary directories for `HELM_CACHE_HOME`, `HELM_CONFIG_HOME` and `HELM_DATA_HOME` which are removed after the step run. This avoids corrupted repository indexes when several helm executions run in parallel on the same agent.")
+
+	cmd.Flags().StringVar(&stepConfig.ReadinessTimeout, "readinessTimeout", `5m`, "Maximum time to wait for the `readinessChecks` to become ready, e.g. `10m`.")
+	cmd.Flags().StringVar(&stepConfig.CleanupSelector, "cleanupSelector", os.Getenv("PIPER_cleanupSelector"), "Label selector (e.g. `app.kubernetes.io/instance=my-release`) of resources which are deleted from the namespace after `uninstall`, e.g. persistent volume claims which are not owned by helm.\nThe matching resources are listed before deletion and only the listed resources are deleted. With `dryRunOnly` the resources are only listed.\n")
+	cmd.Flags().StringSliceVar(&stepConfig.CleanupKinds, "cleanupKinds", []string{`persistentvolumeclaims`, `secrets`, `configmaps`}, "Kinds of resources which are deleted via `cleanupSelector`.")
+	cmd.Flags().StringSliceVar(&stepConfig.DebugCommands, "debugCommands", []string{}, "Helm commands which get the `--debug` flag in verbose mode, e.g. `['lint', 'template']`. By default all commands are debugged.\nSince helm might print the values of a release including secrets with `--debug`, debugging can be restricted to commands where this is safe.\nPossible values are `repo add`, `upgrade`, `install`, `uninstall`, `lint`, `package`, `test`, `template` (used by `dryRunOnly`), `show values`, `get manifest` and `list`.\n")
+	cmd.Flags().BoolVar(&stepConfig.TruncateReleaseName, "truncateReleaseName", false, "Helm release names are limited to 53 characters. If enabled, longer values of `deploymentName` (e.g. containing branch names) are truncated\nand suffixed with a short hash of the full name to keep them unique. The resulting release name is logged.\n")
+	cmd.Flags().BoolVar(&stepConfig.TakeOwnership, "takeOwnership", false, "Lets `upgrade` and `install` adopt existing resources which were not created by helm into the release instead of failing with `invalid ownership metadata`.\nRequires helm 3.17 or newer, the step fails for older helm versions.\n")
+
+	cmd.Flags().IntVar(&stepConfig.HistoryMax, "historyMax", 0, "Maximum number of revisions stored for a release by `upgrade` (helm's `--history-max`) in order to limit the number of release secrets in the namespace.\nIf not set, the default of helm (10) is used.\n")
+	cmd.Flags().BoolVar(&stepConfig.KeepHistory, "keepHistory", false, "Remove all associated resources but keep the release history (only used by `uninstall`).")
+	cmd.Flags().StringVar(&stepConfig.Cascade, "cascade", os.Getenv("PIPER_cascade"), "Deletion strategy for the dependents of the resources of the release (helm's `--cascade`, only used by `uninstall`), e.g. `foreground` waits until all dependents with finalizers have been deleted.\nIf not set, helm's default (`background`) is used. Requires helm 3.12 or newer.\n")
+	cmd.Flags().StringVar(&stepConfig.ResultFile, "resultFile", os.Getenv("PIPER_resultFile"), "Path of a JSON file into which a machine-readable summary (command, release, namespace, status, revision, duration, error, release notes) of each executed helm command is written.")
+	cmd.Flags().BoolVar(&stepConfig.BackupValues, "backupValues", false, "Backs up the user supplied values of the deployed release (`helm get values`) before `upgrade`, e.g. to restore the exact values after a failed upgrade.\nNothing is backed up for the first deployment of a release.\n")
+	cmd.Flags().StringVar(&stepConfig.ValuesBackupFile, "valuesBackupFile", os.Getenv("PIPER_valuesBackupFile"), "Path of the values backup, see `backupValues`. Defaults to `<release>-values-backup.yaml`.")
+	cmd.Flags().StringVar(&stepConfig.DeployRecordFile, "deployRecordFile", os.Getenv("PIPER_deployRecordFile"), "Path of a JSON file into which a record of the deployment is written after a successful `upgrade` or `install`.\nThe record contains release name, namespace, revision, chart name, chart version and app version as well as repository, tag and digest of all images referenced by the values.\n")
+	cmd.Flags().StringVar(&stepConfig.RenderFileMode, "renderFileMode", `0700`, "Octal file mode used when writing the rendered value files, e.g. `0644` in case the files need to be readable by other users in later stages. The mode must be between `0400` and `0777`.")
+	cmd.Flags().StringVar(&stepConfig.DiffReportFile, "diffReportFile", os.Getenv("PIPER_diffReportFile"), "Path of a JSON report of the changes found by `diff`, e.g. `helm-diff.json`. The report can be used as body of a GitHub issue via the `reportFilePath` of the step `githubCreateIssue`.\nThe report contains the `type` (`helmDiff`), the `schemaVersion`, the `release`, `namespace` and `chart`, whether there are `changes` and the `diff` itself.\n")
+	cmd.Flags().IntVar(&stepConfig.UpgradeRetries, "upgradeRetries", 0, "Number of times `upgrade` is retried after a transient cluster error, e.g. `connection refused`, `i/o timeout` or `TLS handshake timeout` while calling the Kubernetes API.\nOther failures are not retried. The interval between two attempts starts with 10 seconds and is doubled after every attempt up to 2 minutes.\nIn case of an atomic upgrade a release which is left in a pending state by the failed attempt is rolled back before the next attempt.\n")
+	cmd.Flags().StringVar(&stepConfig.HttpsProxy, "httpsProxy", os.Getenv("PIPER_httpsProxy"), "Proxy for HTTPS connections of helm, e.g. for adding repositories and pulling charts from OCI registries, e.g. `http://proxy.corp:8080`.\nIf not set, `HTTPS_PROXY` (or `https_proxy`) of the step's environment is used. The proxy is passed to helm as `HTTPS_PROXY` and `https_proxy`.\n")
+	cmd.Flags().StringVar(&stepConfig.HttpProxy, "httpProxy", os.Getenv("PIPER_httpProxy"), "Proxy for HTTP connections of helm. If not set, `HTTP_PROXY` (or `http_proxy`) of the step's environment is used.\n")
+	cmd.Flags().StringVar(&stepConfig.NoProxy, "noProxy", os.Getenv("PIPER_noProxy"), "Comma separated list of hosts which helm connects to without proxy, e.g. the Kubernetes API server: `10.0.0.1,.svc.cluster.local`.\nIf not set, `NO_PROXY` (or `no_proxy`) of the step's environment is used.\n")
+	cmd.Flags().StringVar(&stepConfig.ProgressInterval, "progressInterval", os.Getenv("PIPER_progressInterval"), "Interval as duration (e.g. `30s`) for logging that a long running helm call is still in progress, e.g. `Still waiting for helm upgrade of release 'my-app', 1m30s elapsed`.\nThis applies to `upgrade`, `install`, `uninstall`, `rollback` and `test`, which may wait for the cluster for a long time. If not set, no progress is logged.\n")
+	cmd.Flags().BoolVar(&stepConfig.ValidateValuesSchema, "validateValuesSchema", false, "Validates the values of the release against the `values.schema.json` of a local chart before `upgrade` or `install`, i.e. the default values of the chart, the value files, the `--set` overrides of `additionalParameters`, `setJSONValues` and the secret values.\nAll invalid values are reported at once with their path, e.g. `image.tag: Invalid type. Expected: string, given: integer`. Schemas of subcharts are not considered, these are still validated by helm.\n")
+	cmd.Flags().BoolVar(&stepConfig.UseSecretsPlugin, "useSecretsPlugin", false, "Decrypts the `encryptedHelmValues` via the [helm-secrets](https://github.com/jkroepke/helm-secrets) plugin, e.g. value files encrypted with SOPS.\nThe files are passed as `--values secrets://<file>`, i.e. the plugin decrypts them in memory without writing plaintext files to disk. The step fails if the plugin is not installed.\n")
+	cmd.Flags().StringSliceVar(&stepConfig.EncryptedHelmValues, "encryptedHelmValues", []string{}, "Encrypted value files which are decrypted by the helm-secrets plugin, see `useSecretsPlugin`. They are passed to `upgrade`, `install`, `lint` and `diff` after `helmValues`, i.e. they take precedence over these.\nThe encrypted values are not contained in the `mergedValuesFile` and are not validated by `validateValuesSchema`.\n")
+	cmd.Flags().StringSliceVar(&stepConfig.SecretValuePatterns, "secretValuePatterns", []string{}, "Regular expressions matching keys of helm values (e.g. `^license\\.`) whose values are masked in the log, in addition to the default pattern matching keys containing e.g. `password`, `token` or `key`.\nThe keys are matched as dotted paths, e.g. `database.password`. Values of matching keys are masked in the logged helm parameters (`--set`, `--set-string`, `--set-file` and `--set-json`)\nand, if `--debug` is passed to `upgrade` or `install`, in the values printed by helm. Value files are not scanned.\n")
+
+	cmd.Flags().IntVar(&stepConfig.MaxParallel, "maxParallel", 1, "Maximum number of charts which are deployed concurrently in case `charts` are configured. The output of each chart is logged once its deployment is done.")
+	cmd.Flags().BoolVar(&stepConfig.ExpandEnv, "expandEnv", false, "Replaces references to environment variables in the form `${NAME}` in the value files, e.g. `commit: ${CI_COMMIT_SHA}`, before the templates are rendered.\nReferences within template actions (between `templateStartDelimiter` and `templateEndDelimiter`) are not replaced, references to undefined variables are kept as is.\n")
 	cmd.Flags().StringVar(&stepConfig.TemplateStartDelimiter, "templateStartDelimiter", `{{`, "When templating value files, use this start delimiter.")
 	cmd.Flags().StringVar(&stepConfig.TemplateEndDelimiter, "templateEndDelimiter", `}}`, "When templating value files, use this end delimiter.")
 
 	cmd.MarkFlagRequired("image")
+	cmd.Flags().MarkDeprecated("keepFailedDeployments", "This parameter is deprecated, please use [atomic](#atomic) instead. Setting it to `true` still disables `atomic`.")
 }
 
 // retrieve step metadata
@@ -248,6 +381,7 @@ func helmExecuteMetadata() config.StepData {
 					{Name: "kubeConfigFileCredentialsId", Description: "Jenkins 'Secret file' credentials ID containing kubeconfig file. Details can be found in the [Kubernetes documentation](https://kubernetes.io/docs/concepts/configuration/organize-cluster-access-kubeconfig/).", Type: "jenkins", Aliases: []config.Alias{{Name: "kubeCredentialsId", Deprecated: true}}},
 					{Name: "dockerConfigJsonCredentialsId", Description: "Jenkins 'Secret file' credentials ID containing Docker config.json (with registry credential(s)).", Type: "jenkins"},
 					{Name: "targetRepositoryCredentialsId", Description: "Jenkins 'Username Password' credentials ID containing username and password for the Helm Repository authentication", Type: "jenkins"},
+					{Name: "kubeTokenCredentialsId", Description: "Jenkins 'Secret text' credentials ID containing the token of the service account used to authenticate against `kubeAPIServer`.", Type: "jenkins"},
 				},
 				Resources: []config.StepResources{
 					{Name: "deployDescriptor", Type: "stash"},
@@ -271,6 +405,15 @@ func helmExecuteMetadata() config.StepData {
 						Aliases:     []config.Alias{{Name: "helmChartPath"}},
 						Default:     os.Getenv("PIPER_chartPath"),
 					},
+					{
+						Name:        "workingDirectory",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_workingDirectory"),
+					},
 					{
 						Name: "targetRepositoryURL",
 						ResourceRef: []config.ResourceReference{
@@ -361,6 +504,40 @@ func helmExecuteMetadata() config.StepData {
 						Aliases:   []config.Alias{{Name: "helmRepositoryPassword"}},
 						Default:   os.Getenv("PIPER_targetRepositoryPassword"),
 					},
+					{
+						Name:        "publishTargets",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "[]map[string]interface{}",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+					},
+					{
+						Name:        "packageDestination",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_packageDestination"),
+					},
+					{
+						Name:        "resumableUpload",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "uploadHeaders",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "map[string]interface{}",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+					},
 					{
 						Name:        "sourceRepositoryURL",
 						ResourceRef: []config.ResourceReference{},
@@ -430,6 +607,69 @@ func helmExecuteMetadata() config.StepData {
 						Aliases:     []config.Alias{},
 						Default:     300,
 					},
+					{
+						Name:        "helmTimeout",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_helmTimeout"),
+					},
+					{
+						Name:        "upgradeTimeout",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_upgradeTimeout"),
+					},
+					{
+						Name:        "installTimeout",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_installTimeout"),
+					},
+					{
+						Name:        "uninstallTimeout",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_uninstallTimeout"),
+					},
+					{
+						Name:        "testTimeout",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_testTimeout"),
+					},
+					{
+						Name:        "kubeAPITimeout",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_kubeAPITimeout"),
+					},
+					{
+						Name:        "waitForJobs",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
 					{
 						Name:        "helmValues",
 						ResourceRef: []config.ResourceReference{},
@@ -439,6 +679,24 @@ func helmExecuteMetadata() config.StepData {
 						Aliases:     []config.Alias{},
 						Default:     []string{},
 					},
+					{
+						Name:        "setJSONValues",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "[]string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     []string{},
+					},
+					{
+						Name:        "skipRepoAdd",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
 					{
 						Name: "image",
 						ResourceRef: []config.ResourceReference{
@@ -454,14 +712,33 @@ func helmExecuteMetadata() config.StepData {
 						Default:   os.Getenv("PIPER_image"),
 					},
 					{
-						Name:        "keepFailedDeployments",
+						Name:        "atomic",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"GENERAL", "PARAMETERS", "STAGES", "STEPS"},
 						Type:        "bool",
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
+						Default:     true,
+					},
+					{
+						Name:        "cleanupOnFail",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
 						Default:     false,
 					},
+					{
+						Name:               "keepFailedDeployments",
+						ResourceRef:        []config.ResourceReference{},
+						Scope:              []string{"GENERAL", "PARAMETERS", "STAGES", "STEPS"},
+						Type:               "bool",
+						Mandatory:          false,
+						Aliases:            []config.Alias{},
+						Default:            false,
+						DeprecationMessage: "This parameter is deprecated, please use [atomic](#atomic) instead. Setting it to `true` still disables `atomic`.",
+					},
 					{
 						Name: "kubeConfig",
 						ResourceRef: []config.ResourceReference{
@@ -491,6 +768,44 @@ func helmExecuteMetadata() config.StepData {
 						Aliases:     []config.Alias{},
 						Default:     os.Getenv("PIPER_kubeContext"),
 					},
+					{
+						Name: "kubeToken",
+						ResourceRef: []config.ResourceReference{
+							{
+								Name: "kubeTokenCredentialsId",
+								Type: "secret",
+							},
+
+							{
+								Name:    "kubeTokenVaultSecretName",
+								Type:    "vaultSecret",
+								Default: "kube-token",
+							},
+						},
+						Scope:     []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:      "string",
+						Mandatory: false,
+						Aliases:   []config.Alias{},
+						Default:   os.Getenv("PIPER_kubeToken"),
+					},
+					{
+						Name:        "kubeAPIServer",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_kubeAPIServer"),
+					},
+					{
+						Name:        "kubeCACertificate",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_kubeCACertificate"),
+					},
 					{
 						Name:        "namespace",
 						ResourceRef: []config.ResourceReference{},
@@ -547,6 +862,15 @@ func helmExecuteMetadata() config.StepData {
 						Aliases:     []config.Alias{},
 						Default:     os.Getenv("PIPER_dependency"),
 					},
+					{
+						Name:        "dependencyLocalPath",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_dependencyLocalPath"),
+					},
 					{
 						Name:        "packageDependencyUpdate",
 						ResourceRef: []config.ResourceReference{},
@@ -556,6 +880,15 @@ func helmExecuteMetadata() config.StepData {
 						Aliases:     []config.Alias{},
 						Default:     false,
 					},
+					{
+						Name:        "dependencyUpdate",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
 					{
 						Name:        "dumpLogs",
 						ResourceRef: []config.ResourceReference{},
@@ -565,6 +898,15 @@ func helmExecuteMetadata() config.StepData {
 						Aliases:     []config.Alias{},
 						Default:     false,
 					},
+					{
+						Name:        "runTestsAfterDeploy",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
 					{
 						Name:        "filterTest",
 						ResourceRef: []config.ResourceReference{},
@@ -574,6 +916,15 @@ func helmExecuteMetadata() config.StepData {
 						Aliases:     []config.Alias{},
 						Default:     os.Getenv("PIPER_filterTest"),
 					},
+					{
+						Name:        "testResultFile",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_testResultFile"),
+					},
 					{
 						Name:        "customTlsCertificateLinks",
 						ResourceRef: []config.ResourceReference{},
@@ -610,6 +961,395 @@ func helmExecuteMetadata() config.StepData {
 						Aliases:     []config.Alias{},
 						Default:     true,
 					},
+					{
+						Name:        "helmBinary",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"GENERAL", "PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     `helm`,
+					},
+					{
+						Name:        "stepTimeout",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_stepTimeout"),
+					},
+					{
+						Name:        "dryRunOnly",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "dryRunMode",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     `client`,
+					},
+					{
+						Name:        "preflightCheck",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "createNamespace",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"GENERAL", "PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     true,
+					},
+					{
+						Name:        "namespaceLabels",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "map[string]interface{}",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+					},
+					{
+						Name:        "namespaceAnnotations",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "map[string]interface{}",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+					},
+					{
+						Name:        "description",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_description"),
+					},
+					{
+						Name:        "resetValues",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "reuseValues",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "lintWithSubcharts",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "isolatedHelmHome",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "vaultValues",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "map[string]interface{}",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+					},
+					{
+						Name:        "helmEnv",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "map[string]interface{}",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+					},
+					{
+						Name:        "readinessChecks",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "[]map[string]interface{}",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+					},
+					{
+						Name:        "readinessTimeout",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     `5m`,
+					},
+					{
+						Name:        "cleanupSelector",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_cleanupSelector"),
+					},
+					{
+						Name:        "cleanupKinds",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "[]string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     []string{`persistentvolumeclaims`, `secrets`, `configmaps`},
+					},
+					{
+						Name:        "debugCommands",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "[]string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     []string{},
+					},
+					{
+						Name:        "truncateReleaseName",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "takeOwnership",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "releaseLabels",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "map[string]interface{}",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+					},
+					{
+						Name:        "historyMax",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "int",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     0,
+					},
+					{
+						Name:        "keepHistory",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "cascade",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_cascade"),
+					},
+					{
+						Name:        "resultFile",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_resultFile"),
+					},
+					{
+						Name:        "backupValues",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "valuesBackupFile",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_valuesBackupFile"),
+					},
+					{
+						Name:        "deployRecordFile",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_deployRecordFile"),
+					},
+					{
+						Name:        "renderFileMode",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"STEPS", "STAGES", "PARAMETERS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     `0700`,
+					},
+					{
+						Name:        "diffReportFile",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"STEPS", "STAGES", "PARAMETERS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_diffReportFile"),
+					},
+					{
+						Name:        "upgradeRetries",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"STEPS", "STAGES", "PARAMETERS"},
+						Type:        "int",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     0,
+					},
+					{
+						Name:        "httpsProxy",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"GENERAL", "STEPS", "STAGES", "PARAMETERS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_httpsProxy"),
+					},
+					{
+						Name:        "httpProxy",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"GENERAL", "STEPS", "STAGES", "PARAMETERS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_httpProxy"),
+					},
+					{
+						Name:        "noProxy",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"GENERAL", "STEPS", "STAGES", "PARAMETERS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_noProxy"),
+					},
+					{
+						Name:        "progressInterval",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"STEPS", "STAGES", "PARAMETERS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_progressInterval"),
+					},
+					{
+						Name:        "validateValuesSchema",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"STEPS", "STAGES", "PARAMETERS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "useSecretsPlugin",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"STEPS", "STAGES", "PARAMETERS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "encryptedHelmValues",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"STEPS", "STAGES", "PARAMETERS"},
+						Type:        "[]string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     []string{},
+					},
+					{
+						Name:        "secretValuePatterns",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "[]string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     []string{},
+					},
+					{
+						Name:        "charts",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "[]map[string]interface{}",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+					},
+					{
+						Name:        "maxParallel",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "int",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     1,
+					},
+					{
+						Name:        "expandEnv",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"STEPS", "STAGES", "PARAMETERS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
 					{
 						Name:        "templateStartDelimiter",
 						ResourceRef: []config.ResourceReference{},
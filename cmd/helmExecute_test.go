@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"sync"
 	"testing"
 
+	"github.com/SAP/jenkins-library/pkg/kubernetes"
 	"github.com/SAP/jenkins-library/pkg/kubernetes/mocks"
 	"github.com/SAP/jenkins-library/pkg/mock"
 	"github.com/SAP/jenkins-library/pkg/piperenv"
@@ -100,7 +102,7 @@ func TestRunHelmLint(t *testing.T) {
 	for i, testCase := range testTable {
 		t.Run(fmt.Sprint("case ", i), func(t *testing.T) {
 			helmExecute := &mocks.HelmExecutor{}
-			helmExecute.On("RunHelmLint").Return(testCase.methodError)
+			helmExecute.On("RunHelmLint").Return(nil, testCase.methodError)
 
 			err := runHelmExecute(testCase.config, helmExecute, &cpe)
 			if err != nil {
@@ -264,6 +266,44 @@ func TestRunHelmDependency(t *testing.T) {
 	}
 }
 
+func TestRunHelmDiff(t *testing.T) {
+	t.Parallel()
+
+	cpe := helmExecuteCommonPipelineEnvironment{}
+	testTable := []struct {
+		config         helmExecuteOptions
+		methodError    error
+		expectedErrStr string
+	}{
+		{
+			config: helmExecuteOptions{
+				HelmCommand: "diff",
+			},
+			methodError: nil,
+		},
+		{
+			config: helmExecuteOptions{
+				HelmCommand: "diff",
+			},
+			methodError:    errors.New("some error"),
+			expectedErrStr: "failed to execute helm diff: some error",
+		},
+	}
+
+	for i, testCase := range testTable {
+		t.Run(fmt.Sprint("case ", i), func(t *testing.T) {
+			helmExecute := &mocks.HelmExecutor{}
+			helmExecute.On("RunHelmDiff").Return("", testCase.methodError)
+
+			err := runHelmExecute(testCase.config, helmExecute, &cpe)
+			if err != nil {
+				assert.Equal(t, testCase.expectedErrStr, err.Error())
+			}
+		})
+
+	}
+}
+
 func TestRunHelmPush(t *testing.T) {
 	t.Parallel()
 
@@ -349,7 +389,7 @@ func TestRunHelmDefaultCommand(t *testing.T) {
 	for i, testCase := range testTable {
 		t.Run(fmt.Sprint("case ", i), func(t *testing.T) {
 			helmExecute := &mocks.HelmExecutor{}
-			helmExecute.On("RunHelmLint").Return(testCase.methodLintError)
+			helmExecute.On("RunHelmLint").Return(nil, testCase.methodLintError)
 			helmExecute.On("RunHelmDependency").Return(testCase.methodPackageError)
 			helmExecute.On("RunHelmPublish").Return(testCase.methodPublishError)
 
@@ -363,6 +403,74 @@ func TestRunHelmDefaultCommand(t *testing.T) {
 
 }
 
+func TestRunHelmExecuteWithFake(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default command", func(t *testing.T) {
+		cpe := helmExecuteCommonPipelineEnvironment{}
+		helmExecutor := &mocks.FakeHelmExecutor{
+			Outputs: map[string]string{"RunHelmPublish": "https://my.target.repository.local/chart-1.0.0.tgz"},
+		}
+
+		err := runHelmExecute(helmExecuteOptions{Dependency: "update", Publish: true}, helmExecutor, &cpe)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"RunHelmLint", "RunHelmDependency", "RunHelmPublish"}, helmExecutor.Calls())
+		assert.Equal(t, "https://my.target.repository.local/chart-1.0.0.tgz", cpe.custom.helmChartURL)
+	})
+
+	t.Run("failing lint stops the default command", func(t *testing.T) {
+		cpe := helmExecuteCommonPipelineEnvironment{}
+		helmExecutor := &mocks.FakeHelmExecutor{
+			Errors: map[string]error{"RunHelmLint": errors.New("lint failed")},
+		}
+
+		err := runHelmExecute(helmExecuteOptions{}, helmExecutor, &cpe)
+		assert.EqualError(t, err, "failed to execute helm lint: lint failed")
+		assert.False(t, helmExecutor.Called("RunHelmPublish"))
+	})
+}
+
+func TestRunHelmExecuteParallel(t *testing.T) {
+	helmConfig := kubernetes.HelmExecuteOptions{
+		ChartPath:             "./app",
+		DeploymentName:        "app",
+		Namespace:             "dev",
+		HelmDeployWaitSeconds: 60,
+	}
+	charts := []kubernetes.HelmChart{
+		{ChartPath: "./database", DeploymentName: "database"},
+		{ChartPath: "./cache", DeploymentName: "cache", Namespace: "cache"},
+	}
+
+	t.Run("upgrade of all charts", func(t *testing.T) {
+		var mutex sync.Mutex
+		fakes := []*mocks.FakeDeployUtils{}
+		newUtils := func() kubernetes.DeployUtils {
+			mutex.Lock()
+			defer mutex.Unlock()
+			fake := mocks.NewFakeDeployUtils()
+			fakes = append(fakes, fake)
+			return fake
+		}
+
+		err := runHelmExecuteParallel(helmExecuteOptions{HelmCommand: "upgrade", MaxParallel: 2}, helmConfig, charts, newUtils)
+
+		assert.NoError(t, err)
+		releases := []string{}
+		for _, fake := range fakes {
+			for _, params := range fake.Executions("helm") {
+				releases = append(releases, fmt.Sprintf("%v %v %v", params[0], params[1], params[2]))
+			}
+		}
+		assert.ElementsMatch(t, []string{"upgrade app ./app", "upgrade database ./database", "upgrade cache ./cache"}, releases)
+	})
+
+	t.Run("unsupported command", func(t *testing.T) {
+		err := runHelmExecuteParallel(helmExecuteOptions{HelmCommand: "lint"}, helmConfig, charts, nil)
+		assert.EqualError(t, err, "charts are only supported by the helm commands upgrade and install, not by 'lint'")
+	})
+}
+
 func TestParseAndRenderCPETemplate(t *testing.T) {
 	commonPipelineEnvironment := "commonPipelineEnvironment"
 	valuesYaml := []byte(`
@@ -485,3 +593,200 @@ tag: {{ imageTag "test-image" }}
 		})
 	}
 }
+
+func TestRenderCPEAdditionalParameters(t *testing.T) {
+	tmpDir := t.TempDir()
+	cpe := piperenv.CPEMap{
+		"git/commitId":    "abcdef123456",
+		"artifactVersion": "1.0.0-123456789",
+	}
+	require.NoError(t, cpe.WriteToDisk(path.Join(tmpDir, "commonPipelineEnvironment")))
+
+	t.Run("render referenced parameters only", func(t *testing.T) {
+		config := helmExecuteOptions{
+			AdditionalParameters: []string{"--set", `annotations.commit={{ git "commitId" }}`, "--set", "image.tag=latest"},
+		}
+		params, err := renderCPEAdditionalParameters(config, tmpDir)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"--set", "annotations.commit=abcdef123456", "--set", "image.tag=latest"}, params)
+	})
+
+	t.Run("custom delimiters", func(t *testing.T) {
+		config := helmExecuteOptions{
+			AdditionalParameters:   []string{`--set=version=[[ cpe "artifactVersion" ]]`, "--set=template={{ .Values.test }}"},
+			TemplateStartDelimiter: "[[",
+			TemplateEndDelimiter:   "]]",
+		}
+		params, err := renderCPEAdditionalParameters(config, tmpDir)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"--set=version=1.0.0-123456789", "--set=template={{ .Values.test }}"}, params)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		config := helmExecuteOptions{
+			AdditionalParameters: []string{"--set=commit={{ git"},
+		}
+		_, err := renderCPEAdditionalParameters(config, tmpDir)
+		assert.ErrorContains(t, err, "failed to parse template")
+	})
+}
+
+func TestRenderFileMode(t *testing.T) {
+	tt := []struct {
+		mode          string
+		expectedMode  os.FileMode
+		expectedError string
+	}{
+		{mode: "", expectedMode: 0700},
+		{mode: "0700", expectedMode: 0700},
+		{mode: "0644", expectedMode: 0644},
+		{mode: "444", expectedMode: 0444},
+		{mode: "0899", expectedError: "invalid renderFileMode '0899', please provide an octal file mode like 0644: strconv.ParseUint: parsing \"0899\": invalid syntax"},
+		{mode: "04755", expectedError: "invalid renderFileMode '04755', the mode must be between 0400 and 0777 and readable by the owner"},
+		{mode: "0044", expectedError: "invalid renderFileMode '0044', the mode must be between 0400 and 0777 and readable by the owner"},
+	}
+
+	for _, test := range tt {
+		t.Run(test.mode, func(t *testing.T) {
+			mode, err := renderFileMode(test.mode)
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expectedMode, mode)
+			}
+		})
+	}
+
+	t.Run("rendered value file is written with configured mode", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, piperenv.CPEMap{}.WriteToDisk(tmpDir))
+		utils := newHelmMockUtilsBundle()
+		utils.AddFile("values.yaml", []byte("image: test"))
+
+		err := parseAndRenderCPETemplate(helmExecuteOptions{ChartPath: ".", RenderFileMode: "0644"}, tmpDir, utils)
+		assert.NoError(t, err)
+		info, err := utils.Stat("values.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0644), info.Mode())
+	})
+}
+
+func TestExpandTemplateEnv(t *testing.T) {
+	t.Setenv("CI_COMMIT_SHA", "a1b2c3d")
+	t.Setenv("CI_BRANCH", "main")
+
+	tt := []struct {
+		name     string
+		content  string
+		start    string
+		end      string
+		expected string
+	}{
+		{name: "reference", content: "commit: ${CI_COMMIT_SHA}\nbranch: ${CI_BRANCH}", start: "{{", end: "}}", expected: "commit: a1b2c3d\nbranch: main"},
+		{name: "undefined variable", content: "commit: ${UNDEFINED_VARIABLE}", start: "{{", end: "}}", expected: "commit: ${UNDEFINED_VARIABLE}"},
+		{name: "unbraced reference", content: "password: pa$$word$CI_BRANCH", start: "{{", end: "}}", expected: "password: pa$$word$CI_BRANCH"},
+		{name: "reference within action", content: `tag: {{ cpe "${CI_BRANCH}" }}-${CI_BRANCH}`, start: "{{", end: "}}", expected: `tag: {{ cpe "${CI_BRANCH}" }}-main`},
+		{name: "custom delimiters", content: "a: [[ ${CI_BRANCH} ]] {{ ${CI_BRANCH} }}", start: "[[", end: "]]", expected: "a: [[ ${CI_BRANCH} ]] {{ main }}"},
+		{name: "unclosed action", content: "a: ${CI_BRANCH} {{ ${CI_BRANCH}", start: "{{", end: "}}", expected: "a: main {{ ${CI_BRANCH}"},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, expandTemplateEnv(test.content, test.start, test.end))
+		})
+	}
+}
+
+func TestRenderCPENamespace(t *testing.T) {
+	tmpDir := t.TempDir()
+	cpe := piperenv.CPEMap{
+		"custom/environment": "dev",
+		"git/branch":         "feature/test",
+		"custom/empty":       "",
+	}
+	require.NoError(t, cpe.WriteToDisk(path.Join(tmpDir, "commonPipelineEnvironment")))
+
+	tt := []struct {
+		namespace         string
+		expectedNamespace string
+		expectedError     string
+	}{
+		{namespace: "static", expectedNamespace: "static"},
+		{namespace: `app-{{ cpe "custom/environment" }}`, expectedNamespace: "app-dev"},
+		{namespace: `{{ cpecustom "empty" }}`, expectedError: "namespace template '{{ cpecustom \"empty\" }}' rendered to an empty namespace"},
+		{namespace: `app-{{ git "branch" }}`, expectedError: "invalid namespace 'app-feature/test' rendered from 'app-{{ git \"branch\" }}': a namespace must consist of at most 63 lower case alphanumeric characters or '-', and must start and end with an alphanumeric character"},
+		{namespace: "app-{{ cpe", expectedError: "failed to parse template: failed to parse cpe template 'app-{{ cpe': template: cpetemplate:1: unclosed action"},
+	}
+
+	for _, test := range tt {
+		t.Run(test.namespace, func(t *testing.T) {
+			namespace, err := renderCPENamespace(helmExecuteOptions{Namespace: test.namespace}, tmpDir)
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expectedNamespace, namespace)
+			}
+		})
+	}
+}
+
+func TestRenderCPEAppVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+	cpe := piperenv.CPEMap{
+		"artifactVersion": "1.2.3-20240101",
+		"git/commitId":    "a1b2c3d",
+	}
+	require.NoError(t, cpe.WriteToDisk(path.Join(tmpDir, "commonPipelineEnvironment")))
+
+	tt := []struct {
+		appVersion         string
+		expectedAppVersion string
+		expectedError      string
+	}{
+		{appVersion: "", expectedAppVersion: ""},
+		{appVersion: "1.0.0", expectedAppVersion: "1.0.0"},
+		{appVersion: `{{ cpe "artifactVersion" }}`, expectedAppVersion: "1.2.3-20240101"},
+		{appVersion: `{{ cpe "artifactVersion" }}+{{ git "commitId" }}`, expectedAppVersion: "1.2.3-20240101+a1b2c3d"},
+		{appVersion: `{{ cpe "unknown" }}`, expectedError: "app version template '{{ cpe \"unknown\" }}' rendered to an empty app version"},
+	}
+
+	for _, test := range tt {
+		t.Run(test.appVersion, func(t *testing.T) {
+			appVersion, err := renderCPEAppVersion(helmExecuteOptions{AppVersion: test.appVersion}, tmpDir)
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expectedAppVersion, appVersion)
+			}
+		})
+	}
+}
+
+func TestHelmWorkingPath(t *testing.T) {
+	assert.Equal(t, "values.yaml", helmWorkingPath(helmExecuteOptions{}, "values.yaml"))
+	assert.Equal(t, "charts/app/values.yaml", helmWorkingPath(helmExecuteOptions{WorkingDirectory: "charts"}, "app/values.yaml"))
+	assert.Equal(t, "/tmp/values.yaml", helmWorkingPath(helmExecuteOptions{WorkingDirectory: "charts"}, "/tmp/values.yaml"))
+}
+
+func TestStringMap(t *testing.T) {
+	assert.Equal(t, map[string]string{}, stringMap(nil))
+	assert.Equal(t, map[string]string{"AWS_REGION": "eu-central-1", "RETRIES": "3", "ENABLED": "true"},
+		stringMap(map[string]interface{}{"AWS_REGION": "eu-central-1", "RETRIES": 3, "ENABLED": true}))
+}
+
+func TestResolveVaultValues(t *testing.T) {
+	t.Run("values are resolved from vault credentials", func(t *testing.T) {
+		t.Setenv("PIPER_VAULTCREDENTIAL_DB_PASSWORD", "db-s3cr3t")
+		secretValues, err := resolveVaultValues(map[string]interface{}{"database.password": "db-password"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"database.password": "db-s3cr3t"}, secretValues)
+	})
+
+	t.Run("vault credential not exposed", func(t *testing.T) {
+		_, err := resolveVaultValues(map[string]interface{}{"database.password": "unknown-key"})
+		assert.EqualError(t, err, "environment variable \"PIPER_VAULTCREDENTIAL_UNKNOWN_KEY\" is not set (required by value 'database.password'), please add 'unknown-key' to vaultCredentialKeys")
+	})
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/SAP/jenkins-library/pkg/kubernetes"
 	"github.com/SAP/jenkins-library/pkg/log"
 	"github.com/SAP/jenkins-library/pkg/telemetry"
+	"github.com/ghodss/yaml"
 	"github.com/pkg/errors"
 	"helm.sh/helm/v3/pkg/cli/values"
 )
@@ -38,6 +39,12 @@ func runKubernetesDeploy(config kubernetesDeployOptions, telemetryData *telemetr
 	telemetryData.Custom1Label = "deployTool"
 	telemetryData.Custom1 = config.DeployTool
 
+	valuesMapping, err := mergeValuesMappings(config.ValuesMappingFiles, config.ValuesMapping, utils)
+	if err != nil {
+		return err
+	}
+	config.ValuesMapping = valuesMapping
+
 	if config.DeployTool == "helm" || config.DeployTool == "helm3" {
 		err := runHelmDeploy(config, utils, stdout)
 		// download and execute teardown script
@@ -172,6 +179,9 @@ func runHelmDeploy(config kubernetesDeployOptions, utils kubernetes.DeployUtils,
 	if err != nil {
 		return errors.Wrap(err, "failed to map values using 'valuesMapping' configuration")
 	}
+	if err := writeDeploymentValues(config.DeploymentValuesFile, helmValues, utils); err != nil {
+		return err
+	}
 
 	upgradeParams = append(
 		upgradeParams,
@@ -335,6 +345,9 @@ func runKubectlDeploy(config kubernetesDeployOptions, utils kubernetes.DeployUti
 	if err != nil {
 		return errors.Wrap(err, "failed to map values using 'valuesMapping' configuration")
 	}
+	if err := writeDeploymentValues(config.DeploymentValuesFile, values, utils); err != nil {
+		return err
+	}
 
 	re := regexp.MustCompile(`image:[ ]*<image-name>`)
 	placeholderFound := re.Match(appTemplate)
@@ -350,7 +363,13 @@ func runKubectlDeploy(config kubernetesDeployOptions, utils kubernetes.DeployUti
 	}
 
 	buf := bytes.NewBufferString("")
-	tpl, err := template.New("appTemplate").Parse(string(appTemplate))
+	// custom delimiters allow to keep e.g. helm templates in the app template, empty delimiters default to "{{" and "}}"
+	tpl := template.New("appTemplate").Delims(config.TemplateStartDelimiter, config.TemplateEndDelimiter)
+	if config.StrictTemplating {
+		// fail on undefined values instead of rendering "<no value>", e.g. for a typo like .Values.image.repositry
+		tpl = tpl.Option("missingkey=error")
+	}
+	tpl, err = tpl.Parse(string(appTemplate))
 	if err != nil {
 		return errors.Wrap(err, "failed to parse app-template file")
 	}
@@ -434,6 +453,44 @@ func (dv *deploymentValues) mapValues() error {
 	return nil
 }
 
+// mergeValuesMappings merges the mappings of the valuesMappingFiles in the given order and the valuesMapping of the configuration,
+// nested mappings are flattened into paths, e.g. subchart: {image: {tag: image.debug.tag}} to subchart.image.tag: image.debug.tag
+// in case of conflicts the last mapping wins, i.e. valuesMapping overrides all files
+func mergeValuesMappings(files []string, valuesMapping map[string]interface{}, utils kubernetes.DeployUtils) (map[string]interface{}, error) {
+	if len(files) == 0 {
+		return valuesMapping, nil
+	}
+
+	merged := map[string]interface{}{}
+	for _, file := range files {
+		content, err := utils.FileRead(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read valuesMapping file '%v'", file)
+		}
+		fileMapping := map[string]interface{}{}
+		if err := yaml.Unmarshal(content, &fileMapping); err != nil {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return nil, errors.Wrapf(err, "failed to parse valuesMapping file '%v'", file)
+		}
+		flattenValuesMapping("", fileMapping, merged)
+	}
+	flattenValuesMapping("", valuesMapping, merged)
+	return merged, nil
+}
+
+func flattenValuesMapping(prefix string, mapping map[string]interface{}, flattened map[string]interface{}) {
+	for key, value := range mapping {
+		if len(prefix) > 0 {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenValuesMapping(key, nested, flattened)
+			continue
+		}
+		flattened[key] = value
+	}
+}
+
 func (dv deploymentValues) marshal() []string {
 	var result []string
 	for _, item := range dv.values {
@@ -455,6 +512,47 @@ func (dv *deploymentValues) asHelmValues() map[string]interface{} {
 	}
 }
 
+// asJSON serializes the resolved deployment values, e.g. for debugging the valuesMapping, the docker config secret is redacted
+func (dv *deploymentValues) asJSON() ([]byte, error) {
+	values := map[string]string{}
+	for _, item := range dv.values {
+		if item.key == "secret.dockerconfigjson" {
+			values[item.key] = "****"
+			continue
+		}
+		values[item.key] = item.value
+	}
+
+	exported := struct {
+		SingleImage     bool              `json:"singleImage"`
+		ImageRepository string            `json:"imageRepository,omitempty"`
+		ImageTag        string            `json:"imageTag,omitempty"`
+		Values          map[string]string `json:"values"`
+	}{
+		SingleImage: dv.singleImage,
+		Values:      values,
+	}
+	if dv.singleImage {
+		exported.ImageRepository = dv.get("image.repository")
+		exported.ImageTag = dv.get("image.tag")
+	}
+	return json.MarshalIndent(exported, "", "  ")
+}
+
+func writeDeploymentValues(file string, dv *deploymentValues, utils kubernetes.DeployUtils) error {
+	if len(file) == 0 {
+		return nil
+	}
+	content, err := dv.asJSON()
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize deployment values")
+	}
+	if err := utils.FileWrite(file, content, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write deployment values to '%v'", file)
+	}
+	return nil
+}
+
 func createKey(parts ...string) string {
 	escapedParts := make([]string, 0, len(parts))
 	replacer := strings.NewReplacer(".", "_", "-", "_")
@@ -587,6 +685,10 @@ func defineDeploymentValues(config kubernetesDeployOptions, containerRegistry st
 		dv.add(createKey("image", containerImageName, "tag"), containerImageTag)
 	}
 
+	if len(config.ImagePullSecret) > 0 {
+		dv.add("image.pullSecret", config.ImagePullSecret)
+	}
+
 	return dv, nil
 }
 
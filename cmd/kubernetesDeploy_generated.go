@@ -19,6 +19,9 @@ type kubernetesDeployOptions struct {
 	AdditionalParameters       []string               `json:"additionalParameters,omitempty"`
 	APIServer                  string                 `json:"apiServer,omitempty"`
 	AppTemplate                string                 `json:"appTemplate,omitempty"`
+	StrictTemplating           bool                   `json:"strictTemplating,omitempty"`
+	TemplateStartDelimiter     string                 `json:"templateStartDelimiter,omitempty"`
+	TemplateEndDelimiter       string                 `json:"templateEndDelimiter,omitempty"`
 	ChartPath                  string                 `json:"chartPath,omitempty"`
 	ContainerRegistryPassword  string                 `json:"containerRegistryPassword,omitempty"`
 	ContainerImageName         string                 `json:"containerImageName,omitempty"`
@@ -26,6 +29,7 @@ type kubernetesDeployOptions struct {
 	ContainerRegistryURL       string                 `json:"containerRegistryUrl,omitempty"`
 	ContainerRegistryUser      string                 `json:"containerRegistryUser,omitempty"`
 	ContainerRegistrySecret    string                 `json:"containerRegistrySecret,omitempty"`
+	ImagePullSecret            string                 `json:"imagePullSecret,omitempty"`
 	CreateDockerRegistrySecret bool                   `json:"createDockerRegistrySecret,omitempty"`
 	DeploymentName             string                 `json:"deploymentName,omitempty"`
 	DeployTool                 string                 `json:"deployTool,omitempty" validate:"possible-values=kubectl helm helm3"`
@@ -34,6 +38,8 @@ type kubernetesDeployOptions struct {
 	HelmTestWaitSeconds        int                    `json:"helmTestWaitSeconds,omitempty"`
 	HelmValues                 []string               `json:"helmValues,omitempty"`
 	ValuesMapping              map[string]interface{} `json:"valuesMapping,omitempty"`
+	ValuesMappingFiles         []string               `json:"valuesMappingFiles,omitempty"`
+	DeploymentValuesFile       string                 `json:"deploymentValuesFile,omitempty"`
 	RenderSubchartNotes        bool                   `json:"renderSubchartNotes,omitempty"`
 	GithubToken                string                 `json:"githubToken,omitempty"`
 	Image                      string                 `json:"image,omitempty"`
@@ -175,6 +181,9 @@ func addKubernetesDeployFlags(cmd *cobra.Command, stepConfig *kubernetesDeployOp
 	cmd.Flags().StringSliceVar(&stepConfig.AdditionalParameters, "additionalParameters", []string{}, "Defines additional parameters for \"helm install\" or \"kubectl apply\" command.")
 	cmd.Flags().StringVar(&stepConfig.APIServer, "apiServer", os.Getenv("PIPER_apiServer"), "Defines the Url of the API Server of the Kubernetes cluster.")
 	cmd.Flags().StringVar(&stepConfig.AppTemplate, "appTemplate", os.Getenv("PIPER_appTemplate"), "Defines the filename for the kubernetes app template (e.g. k8s_apptemplate.yaml).")
+	cmd.Flags().BoolVar(&stepConfig.StrictTemplating, "strictTemplating", false, "If set, rendering the Helm styled `appTemplate` fails for undefined values, e.g. for a typo like `.Values.image.repositry`.\nBy default undefined values are rendered as `<no value>`.\n")
+	cmd.Flags().StringVar(&stepConfig.TemplateStartDelimiter, "templateStartDelimiter", os.Getenv("PIPER_templateStartDelimiter"), "Start delimiter of the actions in the Helm styled `appTemplate`, e.g. `[[` if the app template contains `{{ }}` which must not be rendered by the step.\nDefaults to `{{`.\n")
+	cmd.Flags().StringVar(&stepConfig.TemplateEndDelimiter, "templateEndDelimiter", os.Getenv("PIPER_templateEndDelimiter"), "End delimiter of the actions in the Helm styled `appTemplate`, e.g. `]]`. Defaults to `}}`.")
 	cmd.Flags().StringVar(&stepConfig.ChartPath, "chartPath", os.Getenv("PIPER_chartPath"), "Defines the chart path for deployments using helm. It is a mandatory parameter when `deployTool:helm` or `deployTool:helm3`.")
 	cmd.Flags().StringVar(&stepConfig.ContainerRegistryPassword, "containerRegistryPassword", os.Getenv("PIPER_containerRegistryPassword"), "Password for container registry access - typically provided by the CI/CD environment.")
 	cmd.Flags().StringVar(&stepConfig.ContainerImageName, "containerImageName", os.Getenv("PIPER_containerImageName"), "Name of the container which will be built - will be used together with `containerImageTag` instead of parameter `containerImage`")
@@ -182,6 +191,7 @@ func addKubernetesDeployFlags(cmd *cobra.Command, stepConfig *kubernetesDeployOp
 	cmd.Flags().StringVar(&stepConfig.ContainerRegistryURL, "containerRegistryUrl", os.Getenv("PIPER_containerRegistryUrl"), "http(s) url of the Container registry where the image to deploy is located.")
 	cmd.Flags().StringVar(&stepConfig.ContainerRegistryUser, "containerRegistryUser", os.Getenv("PIPER_containerRegistryUser"), "Username for container registry access - typically provided by the CI/CD environment.")
 	cmd.Flags().StringVar(&stepConfig.ContainerRegistrySecret, "containerRegistrySecret", `regsecret`, "Name of the container registry secret used for pulling containers from the registry.")
+	cmd.Flags().StringVar(&stepConfig.ImagePullSecret, "imagePullSecret", os.Getenv("PIPER_imagePullSecret"), "Name of the image pull secret which is passed as value `image.pullSecret`, e.g. to reference the pull secret in the `appTemplate` via `{{ .Values.image.pullSecret }}`.\nTypically this is the `containerRegistrySecret`.\n")
 	cmd.Flags().BoolVar(&stepConfig.CreateDockerRegistrySecret, "createDockerRegistrySecret", false, "Only for `deployTool:kubectl`: Toggle to turn on `containerRegistrySecret` creation.")
 	cmd.Flags().StringVar(&stepConfig.DeploymentName, "deploymentName", os.Getenv("PIPER_deploymentName"), "Defines the name of the deployment. It is a mandatory parameter when `deployTool:helm` or `deployTool:helm3`.")
 	cmd.Flags().StringVar(&stepConfig.DeployTool, "deployTool", `kubectl`, "Defines the tool which should be used for deployment.")
@@ -190,6 +200,8 @@ func addKubernetesDeployFlags(cmd *cobra.Command, stepConfig *kubernetesDeployOp
 	cmd.Flags().IntVar(&stepConfig.HelmTestWaitSeconds, "helmTestWaitSeconds", 300, "Number of seconds to wait for any individual Kubernetes operation (like Jobs for hooks). See https://helm.sh/docs/helm/helm_test/#options for further details")
 	cmd.Flags().StringSliceVar(&stepConfig.HelmValues, "helmValues", []string{}, "List of helm values as YAML file reference or URL (as per helm parameter description for `-f` / `--values`)")
 
+	cmd.Flags().StringSliceVar(&stepConfig.ValuesMappingFiles, "valuesMappingFiles", []string{}, "")
+	cmd.Flags().StringVar(&stepConfig.DeploymentValuesFile, "deploymentValuesFile", os.Getenv("PIPER_deploymentValuesFile"), "File to which the values provided by Piper are written as JSON after applying the `valuesMapping`, e.g. to debug the mapping or to use them in downstream steps.\nIt contains the image repository and tag in case of a single image, `singleImage` and all values, the docker config secret is redacted.\n")
 	cmd.Flags().BoolVar(&stepConfig.RenderSubchartNotes, "renderSubchartNotes", true, "If set, render subchart notes along with the parent.")
 	cmd.Flags().StringVar(&stepConfig.GithubToken, "githubToken", os.Getenv("PIPER_githubToken"), "GitHub personal access token as per https://help.github.com/en/github/authenticating-to-github/creating-a-personal-access-token-for-the-command-line")
 	cmd.Flags().StringVar(&stepConfig.Image, "image", os.Getenv("PIPER_image"), "Full name of the image to be deployed.")
@@ -265,6 +277,33 @@ func kubernetesDeployMetadata() config.StepData {
 						Aliases:     []config.Alias{{Name: "k8sAppTemplate"}},
 						Default:     os.Getenv("PIPER_appTemplate"),
 					},
+					{
+						Name:        "strictTemplating",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "templateStartDelimiter",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_templateStartDelimiter"),
+					},
+					{
+						Name:        "templateEndDelimiter",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_templateEndDelimiter"),
+					},
 					{
 						Name: "chartPath",
 						ResourceRef: []config.ResourceReference{
@@ -375,6 +414,15 @@ func kubernetesDeployMetadata() config.StepData {
 						Aliases:     []config.Alias{},
 						Default:     `regsecret`,
 					},
+					{
+						Name:        "imagePullSecret",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_imagePullSecret"),
+					},
 					{
 						Name:        "createDockerRegistrySecret",
 						ResourceRef: []config.ResourceReference{},
@@ -446,6 +494,24 @@ func kubernetesDeployMetadata() config.StepData {
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
 					},
+					{
+						Name:        "valuesMappingFiles",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "[]string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     []string{},
+					},
+					{
+						Name:        "deploymentValuesFile",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_deploymentValuesFile"),
+					},
 					{
 						Name:        "renderSubchartNotes",
 						ResourceRef: []config.ResourceReference{},
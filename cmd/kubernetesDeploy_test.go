@@ -875,6 +875,48 @@ func TestRunKubernetesDeploy(t *testing.T) {
 		assert.Contains(t, mockUtils.Calls[1].Params[pos], "subchart.image.tag=myTag", "Missing update parameter")
 	})
 
+	t.Run("test helm v3 - writes deployment values file", func(t *testing.T) {
+		opts := kubernetesDeployOptions{
+			ContainerRegistryURL:      "https://my.registry:55555",
+			ContainerRegistryUser:     "registryUser",
+			ContainerRegistryPassword: "dummy",
+			ContainerRegistrySecret:   "testSecret",
+			ChartPath:                 "path/to/chart",
+			DeploymentName:            "deploymentName",
+			DeployTool:                "helm3",
+			Image:                     "path/to/Image:latest",
+			Namespace:                 "deploymentNamespace",
+			DockerConfigJSON:          ".pipeline/docker/config.json",
+			DeploymentValuesFile:      "deploymentValues.json",
+		}
+
+		mockUtils := newKubernetesDeployMockUtils()
+		mockUtils.StdoutReturn = map[string]string{
+			`kubectl create secret generic testSecret --from-file=.dockerconfigjson=.pipeline/docker/config.json --type=kubernetes.io/dockerconfigjson --insecure-skip-tls-verify=true --dry-run=client --output=json`: `{"kind": "Secret","data":{".dockerconfigjson": "ThisIsOurBase64EncodedSecret=="}}`,
+		}
+
+		var stdout bytes.Buffer
+		require.NoError(t, runKubernetesDeploy(opts, &telemetry.CustomData{}, mockUtils, &stdout))
+
+		content, err := mockUtils.FileRead("deploymentValues.json")
+		require.NoError(t, err)
+		assert.NotContains(t, string(content), "ThisIsOurBase64EncodedSecret==")
+		assert.JSONEq(t, `{
+			"singleImage": true,
+			"imageRepository": "my.registry:55555/path/to/Image",
+			"imageTag": "latest",
+			"values": {
+				"image.repository": "my.registry:55555/path/to/Image",
+				"image.tag": "latest",
+				"image.path/to/Image.repository": "my.registry:55555/path/to/Image",
+				"image.path/to/Image.tag": "latest",
+				"secret.name": "testSecret",
+				"secret.dockerconfigjson": "****",
+				"imagePullSecrets[0].name": "testSecret"
+			}
+		}`, string(content))
+	})
+
 	t.Run("test helm v3 - with multiple images and incorrect valuesMapping", func(t *testing.T) {
 		opts := kubernetesDeployOptions{
 			ContainerRegistryURL:      "https://my.registry:55555",
@@ -1336,6 +1378,193 @@ image3: {{ .Values.image.myImage_sub1.repository }}:{{ .Values.image.myImage_sub
 		assert.Contains(t, string(appTemplateFileContents), "image: my.registry:55555/myImage:myTag\nimage2: my.registry:55555/myImage:myTag\nimage3: my.registry:55555/myImage-sub1:myTag", "kubectl parameters incorrect")
 	})
 
+	t.Run("test kubectl - strict templating fails for undefined values", func(t *testing.T) {
+		opts := kubernetesDeployOptions{
+			APIServer:               "https://my.api.server",
+			AppTemplate:             "test.yaml",
+			ContainerRegistryURL:    "https://my.registry:55555",
+			ContainerRegistrySecret: "regSecret",
+			DeployTool:              "kubectl",
+			KubeConfig:              "This is my kubeconfig",
+			Namespace:               "deploymentNamespace",
+			DeployCommand:           "apply",
+			Image:                   "path/to/Image:latest",
+			StrictTemplating:        true,
+		}
+
+		mockUtils := newKubernetesDeployMockUtils()
+		mockUtils.AddFile("test.yaml", []byte(`image: {{ .Values.image.repositry }}:{{ .Values.image.tag }}`))
+
+		var stdout bytes.Buffer
+		err := runKubernetesDeploy(opts, &telemetry.CustomData{}, mockUtils, &stdout)
+		assert.EqualError(t, err, `failed to render app-template file: template: appTemplate:1:17: executing "appTemplate" at <.Values.image.repositry>: map has no entry for key "repositry"`)
+		assert.Empty(t, mockUtils.Calls)
+	})
+
+	t.Run("test kubectl - lenient templating renders undefined values", func(t *testing.T) {
+		opts := kubernetesDeployOptions{
+			APIServer:               "https://my.api.server",
+			AppTemplate:             "test.yaml",
+			ContainerRegistryURL:    "https://my.registry:55555",
+			ContainerRegistrySecret: "regSecret",
+			DeployTool:              "kubectl",
+			KubeConfig:              "This is my kubeconfig",
+			Namespace:               "deploymentNamespace",
+			DeployCommand:           "apply",
+			Image:                   "path/to/Image:latest",
+		}
+
+		mockUtils := newKubernetesDeployMockUtils()
+		mockUtils.AddFile("test.yaml", []byte(`image: {{ .Values.image.repositry }}:{{ .Values.image.tag }}`))
+
+		var stdout bytes.Buffer
+		require.NoError(t, runKubernetesDeploy(opts, &telemetry.CustomData{}, mockUtils, &stdout))
+
+		appTemplateFileContents, err := mockUtils.FileRead(opts.AppTemplate)
+		assert.NoError(t, err)
+		assert.Equal(t, "image: <no value>:latest", string(appTemplateFileContents))
+	})
+
+	t.Run("test kubectl - with custom template delimiters", func(t *testing.T) {
+		opts := kubernetesDeployOptions{
+			APIServer:               "https://my.api.server",
+			AppTemplate:             "test.yaml",
+			ContainerRegistryURL:    "https://my.registry:55555",
+			ContainerRegistrySecret: "regSecret",
+			DeployTool:              "kubectl",
+			KubeConfig:              "This is my kubeconfig",
+			Namespace:               "deploymentNamespace",
+			DeployCommand:           "apply",
+			Image:                   "path/to/Image:latest",
+			TemplateStartDelimiter:  "[[",
+			TemplateEndDelimiter:    "]]",
+		}
+
+		mockUtils := newKubernetesDeployMockUtils()
+		mockUtils.AddFile("test.yaml", []byte(`image: [[ .Values.image.repository ]]:[[ .Values.image.tag ]]
+replicas: {{ .Values.replicaCount }}`))
+
+		var stdout bytes.Buffer
+		require.NoError(t, runKubernetesDeploy(opts, &telemetry.CustomData{}, mockUtils, &stdout))
+
+		appTemplateFileContents, err := mockUtils.FileRead(opts.AppTemplate)
+		assert.NoError(t, err)
+		assert.Equal(t, "image: my.registry:55555/path/to/Image:latest\nreplicas: {{ .Values.replicaCount }}", string(appTemplateFileContents))
+	})
+
+	t.Run("test kubectl - with image pull secret", func(t *testing.T) {
+		opts := kubernetesDeployOptions{
+			APIServer:               "https://my.api.server",
+			AppTemplate:             "test.yaml",
+			ContainerRegistryURL:    "https://my.registry:55555",
+			ContainerRegistrySecret: "regSecret",
+			ImagePullSecret:         "regSecret",
+			DeployTool:              "kubectl",
+			KubeConfig:              "This is my kubeconfig",
+			Namespace:               "deploymentNamespace",
+			DeployCommand:           "apply",
+			ImageNames:              []string{"myImage", "myImage-sub1"},
+			ImageNameTags:           []string{"myImage:myTag", "myImage-sub1:myTag"},
+		}
+
+		mockUtils := newKubernetesDeployMockUtils()
+		mockUtils.AddFile("test.yaml", []byte(`imagePullSecrets:
+- name: {{ .Values.image.pullSecret }}
+image: {{ .Values.image.myImage.repository }}:{{ .Values.image.myImage.tag }}`))
+
+		var stdout bytes.Buffer
+		require.NoError(t, runKubernetesDeploy(opts, &telemetry.CustomData{}, mockUtils, &stdout))
+
+		appTemplateFileContents, err := mockUtils.FileRead(opts.AppTemplate)
+		assert.NoError(t, err)
+		assert.Equal(t, "imagePullSecrets:\n- name: regSecret\nimage: my.registry:55555/myImage:myTag", string(appTemplateFileContents))
+	})
+
+	t.Run("test kubectl - with valuesMapping files", func(t *testing.T) {
+		opts := kubernetesDeployOptions{
+			APIServer:               "https://my.api.server",
+			AppTemplate:             "test.yaml",
+			ContainerRegistryURL:    "https://my.registry:55555",
+			ContainerRegistrySecret: "regSecret",
+			DeployTool:              "kubectl",
+			KubeConfig:              "This is my kubeconfig",
+			Namespace:               "deploymentNamespace",
+			DeployCommand:           "apply",
+			ValuesMappingFiles:      []string{"mapping/base.yaml", "mapping/prod.yaml"},
+			ValuesMapping: map[string]interface{}{
+				"sidecar.image.tag": "image.myImage_sub1.tag",
+			},
+			ImageNames:    []string{"myImage", "myImage-sub1"},
+			ImageNameTags: []string{"myImage:myTag", "myImage-sub1:subTag"},
+		}
+
+		mockUtils := newKubernetesDeployMockUtils()
+		mockUtils.AddFile("mapping/base.yaml", []byte("subchart:\n  image:\n    repository: image.myImage.repository\n    tag: image.myImage.tag\nsidecar.image.tag: image.myImage.tag\n"))
+		mockUtils.AddFile("mapping/prod.yaml", []byte("subchart.image.tag: image.myImage_sub1.tag\n"))
+		mockUtils.AddFile("test.yaml", []byte(`image: {{ .Values.subchart.image.repository }}:{{ .Values.subchart.image.tag }}
+sidecar: {{ .Values.sidecar.image.tag }}`))
+
+		var stdout bytes.Buffer
+		require.NoError(t, runKubernetesDeploy(opts, &telemetry.CustomData{}, mockUtils, &stdout))
+
+		appTemplateFileContents, err := mockUtils.FileRead(opts.AppTemplate)
+		assert.NoError(t, err)
+		assert.Equal(t, "image: my.registry:55555/myImage:subTag\nsidecar: subTag", string(appTemplateFileContents))
+	})
+
+	t.Run("test kubectl - with missing valuesMapping file", func(t *testing.T) {
+		opts := kubernetesDeployOptions{
+			AppTemplate:        "test.yaml",
+			DeployTool:         "kubectl",
+			ValuesMappingFiles: []string{"mapping/missing.yaml"},
+		}
+
+		mockUtils := newKubernetesDeployMockUtils()
+
+		var stdout bytes.Buffer
+		err := runKubernetesDeploy(opts, &telemetry.CustomData{}, mockUtils, &stdout)
+		assert.EqualError(t, err, "failed to read valuesMapping file 'mapping/missing.yaml': could not read 'mapping/missing.yaml'")
+		assert.Empty(t, mockUtils.Calls)
+	})
+
+	t.Run("test kubectl - writes deployment values file", func(t *testing.T) {
+		opts := kubernetesDeployOptions{
+			APIServer:               "https://my.api.server",
+			AppTemplate:             "test.yaml",
+			ContainerRegistryURL:    "https://my.registry:55555",
+			ContainerRegistrySecret: "regSecret",
+			DeployTool:              "kubectl",
+			KubeConfig:              "This is my kubeconfig",
+			Namespace:               "deploymentNamespace",
+			DeployCommand:           "apply",
+			ValuesMapping: map[string]interface{}{
+				"subchart.image.tag": "image.myImage.tag",
+			},
+			ImageNames:           []string{"myImage", "myImage-sub1"},
+			ImageNameTags:        []string{"myImage:myTag", "myImage-sub1:myTag"},
+			DeploymentValuesFile: "deploymentValues.json",
+		}
+
+		mockUtils := newKubernetesDeployMockUtils()
+		mockUtils.AddFile("test.yaml", []byte(`image: {{ .Values.image.myImage.repository }}:{{ .Values.image.myImage.tag }}`))
+
+		var stdout bytes.Buffer
+		require.NoError(t, runKubernetesDeploy(opts, &telemetry.CustomData{}, mockUtils, &stdout))
+
+		content, err := mockUtils.FileRead("deploymentValues.json")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"singleImage": false,
+			"values": {
+				"image.myImage.repository": "my.registry:55555/myImage",
+				"image.myImage.tag": "myTag",
+				"image.myImage_sub1.repository": "my.registry:55555/myImage-sub1",
+				"image.myImage_sub1.tag": "myTag",
+				"subchart.image.tag": "myTag"
+			}
+		}`, string(content))
+	})
+
 	t.Run("test kubectl - with multiple images and digests", func(t *testing.T) {
 		opts := kubernetesDeployOptions{
 			APIServer:               "https://my.api.server",
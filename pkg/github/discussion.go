@@ -0,0 +1,221 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/google/go-github/v45/github"
+	"github.com/pkg/errors"
+)
+
+// Targets of CreateIssue
+const (
+	// TargetIssue creates or updates a GitHub issue
+	TargetIssue = "issue"
+	// TargetDiscussion creates or updates a GitHub discussion
+	TargetDiscussion = "discussion"
+)
+
+const discussionRepositoryQuery = `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    id
+    discussionCategories(first: 100) { nodes { id name } }
+  }
+}`
+
+const discussionSearchQuery = `query($query: String!) {
+  search(query: $query, type: DISCUSSION, first: 100) {
+    nodes { ... on Discussion { id number title url } }
+  }
+}`
+
+const createDiscussionMutation = `mutation($repositoryId: ID!, $categoryId: ID!, $title: String!, $body: String!) {
+  createDiscussion(input: {repositoryId: $repositoryId, categoryId: $categoryId, title: $title, body: $body}) {
+    discussion { id number title url }
+  }
+}`
+
+const addDiscussionCommentMutation = `mutation($discussionId: ID!, $body: String!) {
+  addDiscussionComment(input: {discussionId: $discussionId, body: $body}) { comment { id } }
+}`
+
+const addDiscussionReactionMutation = `mutation($subjectId: ID!) {
+  addReaction(input: {subjectId: $subjectId, content: EYES}) { reaction { content } }
+}`
+
+type discussion struct {
+	ID     string `json:"id"`
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+}
+
+// issue maps the discussion to an issue, the node ID is required to add further comments
+func (d *discussion) issue() *github.Issue {
+	return &github.Issue{NodeID: &d.ID, Number: &d.Number, Title: &d.Title, HTMLURL: &d.URL}
+}
+
+// graphQLClient executes requests against the GitHub GraphQL API reusing authentication and transport of the REST client.
+// Discussions are only available via the GraphQL API.
+type graphQLClient struct {
+	client *github.Client
+}
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (g *graphQLClient) do(ctx context.Context, query string, variables map[string]interface{}, data interface{}) error {
+	endpoint := "graphql"
+	// GitHub Enterprise serves the REST API below /api/v3/ and the GraphQL API at /api/graphql
+	if strings.HasSuffix(g.client.BaseURL.Path, "/api/v3/") {
+		endpoint = "../graphql"
+	}
+	req, err := g.client.NewRequest(http.MethodPost, endpoint, graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return errors.Wrap(err, "failed to create GraphQL request")
+	}
+
+	response := graphQLResponse{}
+	resp, err := g.client.Do(ctx, req, &response)
+	if err != nil {
+		if resp != nil {
+			log.Entry().Errorf("GitHub GraphQL API returned response code %v", resp.Status)
+		}
+		return errors.Wrap(err, "GraphQL request failed")
+	}
+	if len(response.Errors) > 0 {
+		messages := []string{}
+		for _, e := range response.Errors {
+			messages = append(messages, e.Message)
+		}
+		return fmt.Errorf("GraphQL request failed: %v", strings.Join(messages, "; "))
+	}
+	if data != nil {
+		if err := json.Unmarshal(response.Data, data); err != nil {
+			return errors.Wrap(err, "failed to parse GraphQL response")
+		}
+	}
+	return nil
+}
+
+// createDiscussion creates a discussion or updates an existing one, the semantics of the options are the same as for issues.
+// The discussion is returned as issue.
+func createDiscussion(ctx context.Context, ghCreateIssueOptions *CreateIssueOptions, client *graphQLClient, ghCreateGistService githubCreateGistService) (*github.Issue, error) {
+	ghCreateIssueOptions.Existing = false
+
+	var existing *discussion
+	if ghCreateIssueOptions.UpdateExisting {
+		if ghCreateIssueOptions.Issue != nil {
+			existing = &discussion{ID: ghCreateIssueOptions.Issue.GetNodeID(), Number: ghCreateIssueOptions.Issue.GetNumber()}
+		} else {
+			var err error
+			existing, err = searchExistingDiscussion(ctx, ghCreateIssueOptions, client)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if existing != nil {
+		ghCreateIssueOptions.Existing = true
+		var err error
+		switch ghCreateIssueOptions.UpdateMode {
+		case UpdateModeNone:
+			log.Entry().Infof("Discussion #%v already exists, leaving it untouched", existing.Number)
+		case UpdateModeReaction:
+			err = client.do(ctx, addDiscussionReactionMutation, map[string]interface{}{"subjectId": existing.ID}, nil)
+		default:
+			var body, comment string
+			if body, err = postedBody(ctx, ghCreateIssueOptions, ghCreateGistService); err != nil {
+				return nil, err
+			}
+			if comment, err = renderComment(ghCreateIssueOptions, body); err != nil {
+				return nil, err
+			}
+			err = client.do(ctx, addDiscussionCommentMutation, map[string]interface{}{"discussionId": existing.ID, "body": comment}, nil)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "error occurred when updating existing discussion")
+		}
+		return existing.issue(), nil
+	}
+
+	repository := struct {
+		Repository struct {
+			ID                   string `json:"id"`
+			DiscussionCategories struct {
+				Nodes []struct {
+					ID   string `json:"id"`
+					Name string `json:"name"`
+				} `json:"nodes"`
+			} `json:"discussionCategories"`
+		} `json:"repository"`
+	}{}
+	if err := client.do(ctx, discussionRepositoryQuery, map[string]interface{}{"owner": ghCreateIssueOptions.Owner, "name": ghCreateIssueOptions.Repository}, &repository); err != nil {
+		return nil, errors.Wrap(err, "error occurred when looking up discussion categories")
+	}
+	categoryID := ""
+	for _, category := range repository.Repository.DiscussionCategories.Nodes {
+		if strings.EqualFold(category.Name, ghCreateIssueOptions.DiscussionCategory) {
+			categoryID = category.ID
+		}
+	}
+	if len(categoryID) == 0 {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return nil, fmt.Errorf("discussion category '%v' not found in repository %v/%v", ghCreateIssueOptions.DiscussionCategory, ghCreateIssueOptions.Owner, ghCreateIssueOptions.Repository)
+	}
+
+	body, err := postedBody(ctx, ghCreateIssueOptions, ghCreateGistService)
+	if err != nil {
+		return nil, err
+	}
+	created := struct {
+		CreateDiscussion struct {
+			Discussion discussion `json:"discussion"`
+		} `json:"createDiscussion"`
+	}{}
+	variables := map[string]interface{}{
+		"repositoryId": repository.Repository.ID,
+		"categoryId":   categoryID,
+		"title":        ghCreateIssueOptions.Title,
+		"body":         body,
+	}
+	if err := client.do(ctx, createDiscussionMutation, variables, &created); err != nil {
+		return nil, errors.Wrap(err, "error occurred when creating discussion")
+	}
+	log.Entry().Debugf("New discussion created: %v", created.CreateDiscussion.Discussion.URL)
+
+	return created.CreateDiscussion.Discussion.issue(), nil
+}
+
+func searchExistingDiscussion(ctx context.Context, ghCreateIssueOptions *CreateIssueOptions, client *graphQLClient) (*discussion, error) {
+	result := struct {
+		Search struct {
+			Nodes []discussion `json:"nodes"`
+		} `json:"search"`
+	}{}
+	queryString := fmt.Sprintf("repo:%v/%v in:title %v", ghCreateIssueOptions.Owner, ghCreateIssueOptions.Repository, ghCreateIssueOptions.Title)
+	if err := client.do(ctx, discussionSearchQuery, map[string]interface{}{"query": queryString}, &result); err != nil {
+		return nil, errors.Wrap(err, "error occurred when looking for existing discussion")
+	}
+	for _, node := range result.Search.Nodes {
+		if node.Title == ghCreateIssueOptions.Title {
+			node := node
+			return &node, nil
+		}
+	}
+	return nil, nil
+}
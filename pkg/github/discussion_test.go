@@ -0,0 +1,201 @@
+//go:build unit
+// +build unit
+
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type graphQLServerMock struct {
+	path     string
+	requests []graphQLRequest
+}
+
+func (g *graphQLServerMock) handler(responses map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		g.path = r.URL.Path
+		request := graphQLRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		g.requests = append(g.requests, request)
+		w.Header().Set("Content-Type", "application/json")
+		for operation, response := range responses {
+			if strings.Contains(request.Query, operation) {
+				fmt.Fprint(w, response)
+				return
+			}
+		}
+		fmt.Fprint(w, `{"errors": [{"message": "unexpected request"}]}`)
+	}
+}
+
+func TestCreateDiscussion(t *testing.T) {
+	repositoryResponse := `{"data": {"repository": {"id": "R_1", "discussionCategories": {"nodes": [{"id": "C_1", "name": "General"}, {"id": "C_2", "name": "Reports"}]}}}}`
+
+	t.Run("create new discussion", func(t *testing.T) {
+		serverMock := graphQLServerMock{}
+		server := httptest.NewServer(serverMock.handler(map[string]string{
+			"discussionCategories": repositoryResponse,
+			"createDiscussion":     `{"data": {"createDiscussion": {"discussion": {"id": "D_1", "number": 5, "title": "This is my title", "url": "https://github.com/TEST/test/discussions/5"}}}}`,
+		}))
+		defer server.Close()
+
+		options := CreateIssueOptions{
+			APIURL:             server.URL,
+			Owner:              "TEST",
+			Repository:         "test",
+			Title:              "This is my title",
+			Body:               []byte("This is my test body"),
+			Target:             TargetDiscussion,
+			DiscussionCategory: "reports",
+		}
+		issue, err := CreateIssue(&options)
+
+		require.NoError(t, err)
+		assert.Equal(t, "/graphql", serverMock.path)
+		assert.Equal(t, 5, issue.GetNumber())
+		assert.Equal(t, "D_1", issue.GetNodeID())
+		assert.False(t, options.Existing)
+		if assert.Len(t, serverMock.requests, 2) {
+			assert.Equal(t, map[string]interface{}{"repositoryId": "R_1", "categoryId": "C_2", "title": "This is my title", "body": "This is my test body"}, serverMock.requests[1].Variables)
+		}
+	})
+
+	t.Run("comment on existing discussion via GitHub Enterprise", func(t *testing.T) {
+		serverMock := graphQLServerMock{}
+		server := httptest.NewServer(serverMock.handler(map[string]string{
+			"search(":              `{"data": {"search": {"nodes": [{"id": "D_0", "number": 4, "title": "This is my title (outdated)"}, {"id": "D_1", "number": 5, "title": "This is my title"}]}}}`,
+			"addDiscussionComment": `{"data": {"addDiscussionComment": {"comment": {"id": "DC_1"}}}}`,
+		}))
+		defer server.Close()
+
+		options := CreateIssueOptions{
+			APIURL:         server.URL + "/api/v3",
+			Owner:          "TEST",
+			Repository:     "test",
+			Title:          "This is my title",
+			Body:           []byte("This is my test body"),
+			UpdateExisting: true,
+			Target:         TargetDiscussion,
+		}
+		issue, err := CreateIssue(&options)
+
+		require.NoError(t, err)
+		assert.Equal(t, "/api/graphql", serverMock.path)
+		assert.Equal(t, 5, issue.GetNumber())
+		assert.True(t, options.Existing)
+		if assert.Len(t, serverMock.requests, 2) {
+			assert.Equal(t, map[string]interface{}{"query": "repo:TEST/test in:title This is my title"}, serverMock.requests[0].Variables)
+			assert.Equal(t, map[string]interface{}{"discussionId": "D_1", "body": "This is my test body"}, serverMock.requests[1].Variables)
+		}
+	})
+
+	t.Run("comment on existing discussion with comment template", func(t *testing.T) {
+		serverMock := graphQLServerMock{}
+		server := httptest.NewServer(serverMock.handler(map[string]string{
+			"search(":              `{"data": {"search": {"nodes": [{"id": "D_1", "number": 5, "title": "This is my title"}]}}}`,
+			"addDiscussionComment": `{"data": {"addDiscussionComment": {"comment": {"id": "DC_1"}}}}`,
+		}))
+		defer server.Close()
+
+		options := CreateIssueOptions{
+			APIURL:          server.URL,
+			Owner:           "TEST",
+			Repository:      "test",
+			Title:           "This is my title",
+			Body:            []byte("This is my test body"),
+			UpdateExisting:  true,
+			Target:          TargetDiscussion,
+			CommentTemplate: "Build {{.BuildURL}}: {{.Body}}",
+			CommentValues:   map[string]string{"BuildURL": "https://ci/42"},
+		}
+		_, err := CreateIssue(&options)
+
+		require.NoError(t, err)
+		if assert.Len(t, serverMock.requests, 2) {
+			assert.Equal(t, map[string]interface{}{"discussionId": "D_1", "body": "Build https://ci/42: This is my test body"}, serverMock.requests[1].Variables)
+		}
+	})
+
+	t.Run("category not found", func(t *testing.T) {
+		serverMock := graphQLServerMock{}
+		server := httptest.NewServer(serverMock.handler(map[string]string{
+			"discussionCategories": repositoryResponse,
+		}))
+		defer server.Close()
+
+		_, err := CreateIssue(&CreateIssueOptions{
+			APIURL:             server.URL,
+			Owner:              "TEST",
+			Repository:         "test",
+			Title:              "This is my title",
+			Target:             TargetDiscussion,
+			DiscussionCategory: "Announcements",
+		})
+
+		assert.EqualError(t, err, "discussion category 'Announcements' not found in repository TEST/test")
+	})
+
+	t.Run("fingerprint not supported", func(t *testing.T) {
+		serverMock := graphQLServerMock{}
+		server := httptest.NewServer(serverMock.handler(map[string]string{}))
+		defer server.Close()
+
+		_, err := CreateIssue(&CreateIssueOptions{
+			APIURL:      server.URL,
+			Owner:       "TEST",
+			Repository:  "test",
+			Title:       "This is my title",
+			Target:      TargetDiscussion,
+			Fingerprint: "my-fingerprint",
+		})
+
+		assert.EqualError(t, err, "a fingerprint is not supported for target 'discussion'")
+		assert.Empty(t, serverMock.requests)
+	})
+
+	t.Run("parent issue not supported", func(t *testing.T) {
+		serverMock := graphQLServerMock{}
+		server := httptest.NewServer(serverMock.handler(map[string]string{}))
+		defer server.Close()
+
+		_, err := CreateIssue(&CreateIssueOptions{
+			APIURL:      server.URL,
+			Owner:       "TEST",
+			Repository:  "test",
+			Title:       "This is my title",
+			Target:      TargetDiscussion,
+			ParentIssue: 7,
+		})
+
+		assert.EqualError(t, err, "a parent issue is not supported for target 'discussion'")
+		assert.Empty(t, serverMock.requests)
+	})
+
+	t.Run("GraphQL error", func(t *testing.T) {
+		serverMock := graphQLServerMock{}
+		server := httptest.NewServer(serverMock.handler(map[string]string{}))
+		defer server.Close()
+
+		_, err := CreateIssue(&CreateIssueOptions{
+			APIURL:     server.URL,
+			Owner:      "TEST",
+			Repository: "test",
+			Title:      "This is my title",
+			Target:     TargetDiscussion,
+		})
+
+		assert.EqualError(t, err, "error occurred when looking up discussion categories: GraphQL request failed: unexpected request")
+	})
+}
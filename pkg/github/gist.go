@@ -0,0 +1,96 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/google/go-github/v45/github"
+	"github.com/pkg/errors"
+)
+
+type githubCreateGistService interface {
+	Create(ctx context.Context, gist *github.Gist) (*github.Gist, *github.Response, error)
+}
+
+var gistFileNameRegex = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// postedBody returns the body which is posted as body or comment of the issue. A body exceeding MaxBodyBytes is moved to a gist
+// only at this point, thus no gist is created if the body is not posted at all, e.g. for an existing issue in update mode none.
+func postedBody(ctx context.Context, ghCreateIssueOptions *CreateIssueOptions, ghCreateGistService githubCreateGistService) (string, error) {
+	if ghCreateIssueOptions.MaxBodyBytes > 0 && len(ghCreateIssueOptions.Body) > ghCreateIssueOptions.MaxBodyBytes {
+		body, err := moveBodyToGist(ctx, ghCreateIssueOptions, ghCreateGistService)
+		if err != nil {
+			return "", err
+		}
+		ghCreateIssueOptions.Body = body
+	}
+	return string(ghCreateIssueOptions.Body), nil
+}
+
+// moveBodyToGist stores the complete body in a secret gist and returns a summary of the body which links to the gist
+// and fits into MaxBodyBytes
+func moveBodyToGist(ctx context.Context, ghCreateIssueOptions *CreateIssueOptions, ghCreateGistService githubCreateGistService) ([]byte, error) {
+	fileName := strings.Trim(gistFileNameRegex.ReplaceAllString(ghCreateIssueOptions.Title, "-"), "-") + ".md"
+	content := string(ghCreateIssueOptions.Body)
+	public := false
+	gist := &github.Gist{
+		Description: &ghCreateIssueOptions.Title,
+		Public:      &public,
+		Files:       map[github.GistFilename]github.GistFile{github.GistFilename(fileName): {Content: &content}},
+	}
+
+	log.Entry().Infof("Body exceeds %v bytes, creating gist", ghCreateIssueOptions.MaxBodyBytes)
+	created, resp, err := ghCreateGistService.Create(ctx, gist)
+	if err != nil {
+		if resp != nil {
+			log.Entry().Errorf("GitHub create gist returned response code %v", resp.Status)
+			// GitHub responds with 404 in case the token is not allowed to create gists
+			if (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden) && !hasScope(resp, "gist") {
+				log.SetErrorCategory(log.ErrorConfiguration)
+				return nil, fmt.Errorf("failed to create gist for body exceeding %v bytes, the token requires the 'gist' scope: %w", ghCreateIssueOptions.MaxBodyBytes, err)
+			}
+		}
+		return nil, errors.Wrap(err, "error occurred when creating gist")
+	}
+
+	footer := fmt.Sprintf("\n\n---\nThe content exceeds %v bytes and has been truncated, the complete content is available at %v", ghCreateIssueOptions.MaxBodyBytes, created.GetHTMLURL())
+	return []byte(truncateBody(content, ghCreateIssueOptions.MaxBodyBytes-len(footer)) + footer), nil
+}
+
+// hasScope checks the OAuth scopes of the token as reported by GitHub, tokens without scope information (e.g. GitHub App tokens) are considered to have the scope
+func hasScope(resp *github.Response, scope string) bool {
+	scopes := resp.Header.Values("X-OAuth-Scopes")
+	if len(scopes) == 0 {
+		return true
+	}
+	for _, s := range strings.Split(strings.Join(scopes, ","), ",") {
+		if strings.TrimSpace(s) == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateBody cuts the body to at most maxBytes, preferably at a line break and never within a multi-byte character
+func truncateBody(body string, maxBytes int) string {
+	if maxBytes <= 0 {
+		return ""
+	}
+	if len(body) <= maxBytes {
+		return body
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	truncated := body[:cut]
+	if i := strings.LastIndex(truncated, "\n"); i > 0 {
+		truncated = truncated[:i]
+	}
+	return truncated
+}
@@ -0,0 +1,115 @@
+//go:build unit
+// +build unit
+
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v45/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateIssueWithGist(t *testing.T) {
+	body := strings.Repeat("finding\n", 100)
+
+	t.Run("body exceeding maximum size is moved to gist", func(t *testing.T) {
+		var gist github.Gist
+		var issue github.IssueRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/gists":
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&gist))
+				fmt.Fprint(w, `{"html_url": "https://gist.github.com/abc"}`)
+			case "/repos/TEST/test/issues":
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&issue))
+				fmt.Fprint(w, `{"number": 1}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		_, err := CreateIssue(&CreateIssueOptions{
+			APIURL:       server.URL,
+			Owner:        "TEST",
+			Repository:   "test",
+			Title:        "Scan report: my/project",
+			Body:         []byte(body),
+			MaxBodyBytes: 300,
+		})
+
+		require.NoError(t, err)
+		assert.False(t, gist.GetPublic())
+		file := gist.Files["Scan-report-my-project.md"]
+		assert.Equal(t, body, file.GetContent())
+		assert.LessOrEqual(t, len(issue.GetBody()), 300)
+		assert.True(t, strings.HasPrefix(issue.GetBody(), "finding\nfinding\n"))
+		assert.True(t, strings.HasSuffix(issue.GetBody(), "the complete content is available at https://gist.github.com/abc"))
+	})
+
+	t.Run("no gist for existing issue left untouched", func(t *testing.T) {
+		gistCreated := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/gists":
+				gistCreated = true
+				fmt.Fprint(w, `{"html_url": "https://gist.github.com/abc"}`)
+			case "/search/issues":
+				fmt.Fprint(w, `{"items": [{"number": 1, "title": "Scan report"}]}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		options := &CreateIssueOptions{
+			APIURL:         server.URL,
+			Owner:          "TEST",
+			Repository:     "test",
+			Title:          "Scan report",
+			Body:           []byte(body),
+			MaxBodyBytes:   300,
+			UpdateExisting: true,
+			UpdateMode:     UpdateModeNone,
+		}
+		issue, err := CreateIssue(options)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, issue.GetNumber())
+		assert.True(t, options.Existing)
+		assert.False(t, gistCreated)
+	})
+
+	t.Run("token without gist scope", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-OAuth-Scopes", "repo, read:org")
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		_, err := CreateIssue(&CreateIssueOptions{
+			APIURL:       server.URL,
+			Owner:        "TEST",
+			Repository:   "test",
+			Title:        "Scan report",
+			Body:         []byte(body),
+			MaxBodyBytes: 300,
+		})
+
+		assert.ErrorContains(t, err, "failed to create gist for body exceeding 300 bytes, the token requires the 'gist' scope")
+	})
+}
+
+func TestTruncateBody(t *testing.T) {
+	assert.Equal(t, "short", truncateBody("short", 10))
+	assert.Equal(t, "line 1", truncateBody("line 1\nline 2", 10))
+	assert.Equal(t, "ab", truncateBody("abäö", 3))
+	assert.Equal(t, "", truncateBody("abc", -5))
+}
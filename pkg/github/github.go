@@ -2,9 +2,13 @@ package github
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
+	"text/template"
+	"time"
 
 	piperhttp "github.com/SAP/jenkins-library/pkg/http"
 	"github.com/SAP/jenkins-library/pkg/log"
@@ -25,9 +29,39 @@ type githubCreateCommentService interface {
 	CreateComment(ctx context.Context, owner string, repo string, number int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error)
 }
 
+type githubListCommentsService interface {
+	ListComments(ctx context.Context, owner string, repo string, number int, opts *github.IssueListCommentsOptions) ([]*github.IssueComment, *github.Response, error)
+}
+
+type githubGetUserService interface {
+	Get(ctx context.Context, user string) (*github.User, *github.Response, error)
+}
+
+type githubCreateReactionService interface {
+	CreateIssueReaction(ctx context.Context, owner, repo string, number int, content string) (*github.Reaction, *github.Response, error)
+	CreateIssueCommentReaction(ctx context.Context, owner, repo string, id int64, content string) (*github.Reaction, *github.Response, error)
+}
+
+// Update modes defining how an existing issue is updated
+const (
+	// UpdateModeComment adds the body as comment to the existing issue
+	UpdateModeComment = "comment"
+	// UpdateModeReaction adds a reaction to the latest comment of the existing issue written by the user of the token
+	UpdateModeReaction = "reaction"
+	// UpdateModeNone leaves the existing issue untouched
+	UpdateModeNone = "none"
+)
+
+// updateReaction is the reaction added to an existing issue in update mode reaction
+const updateReaction = "eyes"
+
+// MaxIssueBodySize is the maximum size of the body of an issue or comment accepted by GitHub
+const MaxIssueBodySize = 65536
+
 // CreateIssueOptions to configure the creation
 type CreateIssueOptions struct {
 	APIURL         string        `json:"apiUrl,omitempty"`
+	UploadURL      string        `json:"uploadUrl,omitempty"`
 	Assignees      []string      `json:"assignees,omitempty"`
 	Body           []byte        `json:"body,omitempty"`
 	Owner          string        `json:"owner,omitempty"`
@@ -37,10 +71,47 @@ type CreateIssueOptions struct {
 	Token          string        `json:"token,omitempty"`
 	TrustedCerts   []string      `json:"trustedCerts,omitempty"`
 	Issue          *github.Issue `json:"issue,omitempty"`
+	UpdateMode     string        `json:"updateMode,omitempty"`
+	// Target defines whether an issue (default) or a discussion is created
+	Target             string `json:"target,omitempty"`
+	DiscussionCategory string `json:"discussionCategory,omitempty"`
+	// Fingerprint identifies the issue independent of its title via a hidden marker in the issue body
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// MaxBodyBytes defines the maximum size of the body, larger bodies are stored in a secret gist which is linked in the body
+	MaxBodyBytes int `json:"maxBodyBytes,omitempty"`
+	// HTTPTimeout limits the duration of each request to GitHub, 0 means no limit
+	HTTPTimeout time.Duration `json:"-"`
+	// UserAgent identifies the step in the requests to GitHub
+	UserAgent string `json:"userAgent,omitempty"`
+	// CommentTemplate defines the comment added to an existing issue as text/template, the body is available as {{.Body}}
+	CommentTemplate string `json:"commentTemplate,omitempty"`
+	// CommentValues are the values available in CommentTemplate in addition to the body, e.g. {{.Commit}}
+	CommentValues map[string]string `json:"-"`
+	// IssueNumber identifies the issue to update directly instead of searching for an existing issue
+	IssueNumber int `json:"issueNumber,omitempty"`
+	// ReopenClosed reopens the issue identified by IssueNumber in case it is closed
+	ReopenClosed bool `json:"reopenClosed,omitempty"`
+	// ParentIssue is the number of an issue to which the issue is added as task list item
+	ParentIssue int `json:"parentIssue,omitempty"`
+	// Existing is set by CreateIssue in case an existing issue has been found instead of creating a new one
+	Existing bool `json:"-"`
+}
+
+// ClientOptions defines optional settings of the GitHub client
+type ClientOptions struct {
+	// Timeout limits the duration of a request including reading the response body, 0 means no limit
+	Timeout time.Duration
+	// UserAgent identifies the client in the requests, the default of go-github is used if empty
+	UserAgent string
 }
 
 // NewClient creates a new GitHub client using an OAuth token for authentication
 func NewClient(token, apiURL, uploadURL string, trustedCerts []string) (context.Context, *github.Client, error) {
+	return NewClientWithOptions(token, apiURL, uploadURL, trustedCerts, ClientOptions{})
+}
+
+// NewClientWithOptions creates a new GitHub client using an OAuth token for authentication and the given client options
+func NewClientWithOptions(token, apiURL, uploadURL string, trustedCerts []string, options ClientOptions) (context.Context, *github.Client, error) {
 	httpClient := piperhttp.Client{}
 	httpClient.SetOptions(piperhttp.ClientOptions{
 		TrustedCerts:             trustedCerts,
@@ -51,6 +122,8 @@ func NewClient(token, apiURL, uploadURL string, trustedCerts []string) (context.
 	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, stdClient)
 	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token, TokenType: "Bearer"})
 	tc := oauth2.NewClient(ctx, ts)
+	// the OAuth client only takes over the transport of the standard client
+	tc.Timeout = options.Timeout
 
 	if !strings.HasSuffix(apiURL, "/") {
 		apiURL += "/"
@@ -72,28 +145,56 @@ func NewClient(token, apiURL, uploadURL string, trustedCerts []string) (context.
 
 	client.BaseURL = baseURL
 	client.UploadURL = uploadTargetURL
+	if len(options.UserAgent) > 0 {
+		client.UserAgent = options.UserAgent
+	}
 	return ctx, client, nil
 }
 
 func CreateIssue(ghCreateIssueOptions *CreateIssueOptions) (*github.Issue, error) {
-	ctx, client, err := NewClient(ghCreateIssueOptions.Token, ghCreateIssueOptions.APIURL, "", ghCreateIssueOptions.TrustedCerts)
+	ctx, client, err := NewClientWithOptions(ghCreateIssueOptions.Token, ghCreateIssueOptions.APIURL, ghCreateIssueOptions.UploadURL, ghCreateIssueOptions.TrustedCerts,
+		ClientOptions{Timeout: ghCreateIssueOptions.HTTPTimeout, UserAgent: ghCreateIssueOptions.UserAgent})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get GitHub client")
 	}
-	return createIssueLocal(ctx, ghCreateIssueOptions, client.Issues, client.Search, client.Issues)
+	if ghCreateIssueOptions.Target == TargetDiscussion && len(ghCreateIssueOptions.Fingerprint) > 0 {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return nil, fmt.Errorf("a fingerprint is not supported for target '%v'", TargetDiscussion)
+	}
+	if ghCreateIssueOptions.Target == TargetDiscussion && ghCreateIssueOptions.ParentIssue > 0 {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return nil, fmt.Errorf("a parent issue is not supported for target '%v'", TargetDiscussion)
+	}
+	if ghCreateIssueOptions.IssueNumber > 0 && ghCreateIssueOptions.Issue == nil {
+		if ghCreateIssueOptions.Target == TargetDiscussion {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return nil, fmt.Errorf("an issue number is not supported for target '%v'", TargetDiscussion)
+		}
+		if ghCreateIssueOptions.Issue, err = issueByNumber(ctx, ghCreateIssueOptions, client.Issues); err != nil {
+			return nil, err
+		}
+		ghCreateIssueOptions.UpdateExisting = true
+	}
+	if ghCreateIssueOptions.Target == TargetDiscussion {
+		return createDiscussion(ctx, ghCreateIssueOptions, &graphQLClient{client: client}, client.Gists)
+	}
+	issue, err := createIssueLocal(ctx, ghCreateIssueOptions, client.Issues, client.Search, client.Issues, client.Issues, client.Reactions, client.Users, client.Gists)
+	if err != nil {
+		return nil, err
+	}
+	if ghCreateIssueOptions.ParentIssue > 0 {
+		if err := linkToParentIssue(ctx, ghCreateIssueOptions, issue, client.Issues); err != nil {
+			return nil, err
+		}
+	}
+	return issue, nil
 }
 
-func createIssueLocal(ctx context.Context, ghCreateIssueOptions *CreateIssueOptions, ghCreateIssueService githubCreateIssueService, ghSearchIssuesService githubSearchIssuesService, ghCreateCommentService githubCreateCommentService) (*github.Issue, error) {
+func createIssueLocal(ctx context.Context, ghCreateIssueOptions *CreateIssueOptions, ghCreateIssueService githubCreateIssueService, ghSearchIssuesService githubSearchIssuesService, ghCreateCommentService githubCreateCommentService, ghListCommentsService githubListCommentsService, ghCreateReactionService githubCreateReactionService, ghGetUserService githubGetUserService, ghCreateGistService githubCreateGistService) (*github.Issue, error) {
+	ghCreateIssueOptions.Existing = false
 	issue := github.IssueRequest{
 		Title: &ghCreateIssueOptions.Title,
 	}
-	var bodyString string
-	if len(ghCreateIssueOptions.Body) > 0 {
-		bodyString = string(ghCreateIssueOptions.Body)
-	} else {
-		bodyString = ""
-	}
-	issue.Body = &bodyString
 	if len(ghCreateIssueOptions.Assignees) > 0 {
 		issue.Assignees = &ghCreateIssueOptions.Assignees
 	} else {
@@ -105,35 +206,44 @@ func createIssueLocal(ctx context.Context, ghCreateIssueOptions *CreateIssueOpti
 	if ghCreateIssueOptions.UpdateExisting {
 		existingIssue = ghCreateIssueOptions.Issue
 		if existingIssue == nil {
-			queryString := fmt.Sprintf("is:open is:issue repo:%v/%v in:title %v", ghCreateIssueOptions.Owner, ghCreateIssueOptions.Repository, ghCreateIssueOptions.Title)
-			searchResult, resp, err := ghSearchIssuesService.Issues(ctx, queryString, nil)
-			if err != nil {
-				if resp != nil {
-					log.Entry().Errorf("GitHub search issue returned response code %v", resp.Status)
+			var err error
+			if len(ghCreateIssueOptions.Fingerprint) > 0 {
+				marker := FingerprintMarker(ghCreateIssueOptions.Fingerprint)
+				queryString := fmt.Sprintf("is:open is:issue repo:%v/%v in:body %v", ghCreateIssueOptions.Owner, ghCreateIssueOptions.Repository, fingerprintHash(ghCreateIssueOptions.Fingerprint))
+				existingIssue, err = searchExistingIssue(ctx, queryString, func(i *github.Issue) bool { return strings.Contains(i.GetBody(), marker) }, ghSearchIssuesService)
+				if err != nil {
+					return nil, err
+				}
+				if existingIssue == nil {
+					log.Entry().Debug("No issue with fingerprint found, falling back to title")
 				}
-				return nil, errors.Wrap(err, "error occurred when looking for existing issue")
-			} else {
-				for _, value := range searchResult.Issues {
-					if value != nil && *value.Title == ghCreateIssueOptions.Title {
-						existingIssue = value
-					}
+			}
+			if existingIssue == nil {
+				queryString := fmt.Sprintf("is:open is:issue repo:%v/%v in:title %v", ghCreateIssueOptions.Owner, ghCreateIssueOptions.Repository, ghCreateIssueOptions.Title)
+				existingIssue, err = searchExistingIssue(ctx, queryString, func(i *github.Issue) bool { return i.GetTitle() == ghCreateIssueOptions.Title }, ghSearchIssuesService)
+				if err != nil {
+					return nil, err
 				}
 			}
 		}
 
 		if existingIssue != nil {
-			comment := &github.IssueComment{Body: issue.Body}
-			_, resp, err := ghCreateCommentService.CreateComment(ctx, ghCreateIssueOptions.Owner, ghCreateIssueOptions.Repository, *existingIssue.Number, comment)
-			if err != nil {
-				if resp != nil {
-					log.Entry().Errorf("GitHub create comment returned response code %v", resp.Status)
-				}
-				return nil, errors.Wrap(err, "error occurred when adding comment to existing issue")
+			ghCreateIssueOptions.Existing = true
+			if err := updateExistingIssue(ctx, ghCreateIssueOptions, existingIssue, ghCreateCommentService, ghListCommentsService, ghCreateReactionService, ghGetUserService, ghCreateGistService); err != nil {
+				return nil, err
 			}
 		}
 	}
 
 	if existingIssue == nil {
+		bodyString, err := postedBody(ctx, ghCreateIssueOptions, ghCreateGistService)
+		if err != nil {
+			return nil, err
+		}
+		if len(ghCreateIssueOptions.Fingerprint) > 0 {
+			bodyString += FingerprintMarker(ghCreateIssueOptions.Fingerprint)
+		}
+		issue.Body = &bodyString
 		newIssue, resp, err := ghCreateIssueService.Create(ctx, ghCreateIssueOptions.Owner, ghCreateIssueOptions.Repository, &issue)
 		if err != nil {
 			if resp != nil {
@@ -147,3 +257,199 @@ func createIssueLocal(ctx context.Context, ghCreateIssueOptions *CreateIssueOpti
 
 	return existingIssue, nil
 }
+
+// maxRateLimitWait is the longest time to wait for a reset of the GitHub rate limit before giving up
+const maxRateLimitWait = time.Minute
+
+// FingerprintMarker returns the hidden marker which is appended to the body of an issue created with the given fingerprint
+func FingerprintMarker(fingerprint string) string {
+	return fmt.Sprintf("\n\n<!-- piper-issue-id: %v -->", fingerprintHash(fingerprint))
+}
+
+func fingerprintHash(fingerprint string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(fingerprint)))[:16]
+}
+
+// searchExistingIssue pages through the search results until an issue matching the query and match function is found
+func searchExistingIssue(ctx context.Context, queryString string, match func(*github.Issue) bool, ghSearchIssuesService githubSearchIssuesService) (*github.Issue, error) {
+	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	rateLimitRetried := false
+	for {
+		searchResult, resp, err := ghSearchIssuesService.Issues(ctx, queryString, opts)
+		if err != nil {
+			var rateLimitErr *github.RateLimitError
+			if errors.As(err, &rateLimitErr) && !rateLimitRetried {
+				if wait := time.Until(rateLimitErr.Rate.Reset.Time); wait <= maxRateLimitWait {
+					log.Entry().Infof("GitHub rate limit exceeded, waiting %v for reset", wait.Round(time.Second))
+					time.Sleep(wait)
+					rateLimitRetried = true
+					continue
+				}
+			}
+			if resp != nil {
+				log.Entry().Errorf("GitHub search issue returned response code %v", resp.Status)
+			}
+			return nil, errors.Wrap(err, "error occurred when looking for existing issue")
+		}
+		rateLimitRetried = false
+
+		for _, value := range searchResult.Issues {
+			if value != nil && match(value) {
+				return value, nil
+			}
+		}
+
+		if resp == nil || resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// issueByNumber reads the issue identified by IssueNumber and reopens it if it is closed and ReopenClosed is set
+func issueByNumber(ctx context.Context, ghCreateIssueOptions *CreateIssueOptions, ghEditIssueService githubEditIssueService) (*github.Issue, error) {
+	owner, repository, number := ghCreateIssueOptions.Owner, ghCreateIssueOptions.Repository, ghCreateIssueOptions.IssueNumber
+
+	issue, resp, err := ghEditIssueService.Get(ctx, owner, repository, number)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return nil, fmt.Errorf("issue #%v does not exist in %v/%v", number, owner, repository)
+		}
+		if resp != nil {
+			log.Entry().Errorf("GitHub get issue returned response code %v", resp.Status)
+		}
+		return nil, errors.Wrapf(err, "error occurred when reading issue #%v", number)
+	}
+	if issue.IsPullRequest() {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return nil, fmt.Errorf("#%v in %v/%v is a pull request, not an issue", number, owner, repository)
+	}
+
+	if issue.GetState() == "closed" {
+		if !ghCreateIssueOptions.ReopenClosed {
+			log.Entry().Infof("Issue #%v is closed, updating it without reopening", number)
+			return issue, nil
+		}
+		state := "open"
+		issue, resp, err = ghEditIssueService.Edit(ctx, owner, repository, number, &github.IssueRequest{State: &state})
+		if err != nil {
+			if resp != nil {
+				log.Entry().Errorf("GitHub edit issue returned response code %v", resp.Status)
+			}
+			return nil, errors.Wrapf(err, "error occurred when reopening issue #%v", number)
+		}
+		log.Entry().Infof("Issue #%v reopened", number)
+	}
+	return issue, nil
+}
+
+func updateExistingIssue(ctx context.Context, ghCreateIssueOptions *CreateIssueOptions, existingIssue *github.Issue, ghCreateCommentService githubCreateCommentService, ghListCommentsService githubListCommentsService, ghCreateReactionService githubCreateReactionService, ghGetUserService githubGetUserService, ghCreateGistService githubCreateGistService) error {
+	owner, repository, number := ghCreateIssueOptions.Owner, ghCreateIssueOptions.Repository, existingIssue.GetNumber()
+
+	switch ghCreateIssueOptions.UpdateMode {
+	case UpdateModeNone:
+		log.Entry().Infof("Issue #%v already exists, leaving it untouched", number)
+	case UpdateModeReaction:
+		commentID, err := latestOwnComment(ctx, ghCreateIssueOptions, number, ghListCommentsService, ghGetUserService)
+		if err != nil {
+			return err
+		}
+
+		var resp *github.Response
+		if commentID > 0 {
+			_, resp, err = ghCreateReactionService.CreateIssueCommentReaction(ctx, owner, repository, commentID, updateReaction)
+		} else {
+			_, resp, err = ghCreateReactionService.CreateIssueReaction(ctx, owner, repository, number, updateReaction)
+		}
+		if err != nil {
+			if resp != nil {
+				log.Entry().Errorf("GitHub create reaction returned response code %v", resp.Status)
+			}
+			return errors.Wrap(err, "error occurred when adding reaction to existing issue")
+		}
+	default:
+		body, err := postedBody(ctx, ghCreateIssueOptions, ghCreateGistService)
+		if err != nil {
+			return err
+		}
+		commentBody, err := renderComment(ghCreateIssueOptions, body)
+		if err != nil {
+			return err
+		}
+		comment := &github.IssueComment{Body: &commentBody}
+		_, resp, err := ghCreateCommentService.CreateComment(ctx, owner, repository, number, comment)
+		if err != nil {
+			if resp != nil {
+				log.Entry().Errorf("GitHub create comment returned response code %v", resp.Status)
+			}
+			return errors.Wrap(err, "error occurred when adding comment to existing issue")
+		}
+	}
+
+	return nil
+}
+
+// latestOwnComment returns the ID of the latest comment of the issue written by the user of the token, i.e. by previous runs of the step,
+// so that the reaction does not end up on a reply of someone else. 0 is returned if there is no such comment.
+func latestOwnComment(ctx context.Context, ghCreateIssueOptions *CreateIssueOptions, number int, ghListCommentsService githubListCommentsService, ghGetUserService githubGetUserService) (int64, error) {
+	owner, repository := ghCreateIssueOptions.Owner, ghCreateIssueOptions.Repository
+
+	user, resp, err := ghGetUserService.Get(ctx, "")
+	if err != nil {
+		if resp != nil {
+			log.Entry().Errorf("GitHub get user returned response code %v", resp.Status)
+		}
+		return 0, errors.Wrap(err, "error occurred when looking up the user of the token")
+	}
+
+	// the comments of an issue are sorted ascending, thus the pages are searched backwards starting with the last page
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	firstPage, resp, err := ghListCommentsService.ListComments(ctx, owner, repository, number, opts)
+	lastPage := 1
+	if err == nil && resp != nil && resp.LastPage > 1 {
+		lastPage = resp.LastPage
+	}
+	for page := lastPage; page >= 1; page-- {
+		comments := firstPage
+		if page > 1 {
+			opts.Page = page
+			comments, resp, err = ghListCommentsService.ListComments(ctx, owner, repository, number, opts)
+		}
+		if err != nil {
+			if resp != nil {
+				log.Entry().Errorf("GitHub list comments returned response code %v", resp.Status)
+			}
+			return 0, errors.Wrap(err, "error occurred when looking for the latest comment of existing issue")
+		}
+		for i := len(comments) - 1; i >= 0; i-- {
+			if comments[i].GetUser().GetLogin() == user.GetLogin() {
+				return comments[i].GetID(), nil
+			}
+		}
+	}
+	return 0, nil
+}
+
+// renderComment renders CommentTemplate with the body and CommentValues, without template the comment is the body
+func renderComment(ghCreateIssueOptions *CreateIssueOptions, body string) (string, error) {
+	if len(ghCreateIssueOptions.CommentTemplate) == 0 {
+		return body, nil
+	}
+	tmpl, err := template.New("comment").Option("missingkey=error").Parse(ghCreateIssueOptions.CommentTemplate)
+	if err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return "", fmt.Errorf("failed to parse comment template: %w", err)
+	}
+	values := map[string]string{}
+	for key, value := range ghCreateIssueOptions.CommentValues {
+		values[key] = value
+	}
+	values["Body"] = body
+	comment := strings.Builder{}
+	if err := tmpl.Execute(&comment, values); err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return "", fmt.Errorf("failed to render comment template: %w", err)
+	}
+	return comment.String(), nil
+}
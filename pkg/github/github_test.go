@@ -7,8 +7,10 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v45/github"
 	"github.com/stretchr/testify/assert"
@@ -119,7 +121,7 @@ func TestRunGithubCreateIssue(t *testing.T) {
 		}
 
 		// test
-		_, err := createIssueLocal(ctx, &config, &ghCreateIssueService, &ghSearchIssuesMock, &ghCreateCommentMock)
+		_, err := createIssueLocal(ctx, &config, &ghCreateIssueService, &ghSearchIssuesMock, &ghCreateCommentMock, nil, nil, nil, nil)
 
 		// assert
 		assert.NoError(t, err)
@@ -148,7 +150,7 @@ func TestRunGithubCreateIssue(t *testing.T) {
 		}
 
 		// test
-		_, err := createIssueLocal(ctx, &config, nil, &ghSearchIssuesMock, &ghCreateCommentMock)
+		_, err := createIssueLocal(ctx, &config, nil, &ghSearchIssuesMock, &ghCreateCommentMock, nil, nil, nil, nil)
 
 		// assert
 		assert.NoError(t, err)
@@ -181,7 +183,7 @@ func TestRunGithubCreateIssue(t *testing.T) {
 		}
 
 		// test
-		_, err := createIssueLocal(ctx, &config, nil, &ghSearchIssuesMock, &ghCreateCommentMock)
+		_, err := createIssueLocal(ctx, &config, nil, &ghSearchIssuesMock, &ghCreateCommentMock, nil, nil, nil, nil)
 
 		// assert
 		assert.NoError(t, err)
@@ -210,7 +212,7 @@ func TestRunGithubCreateIssue(t *testing.T) {
 		}
 
 		// test
-		_, err := createIssueLocal(ctx, &config, &ghCreateIssueService, &ghSearchIssuesMock, &ghCreateCommentMock)
+		_, err := createIssueLocal(ctx, &config, &ghCreateIssueService, &ghSearchIssuesMock, &ghCreateCommentMock, nil, nil, nil, nil)
 
 		// assert
 		assert.NoError(t, err)
@@ -231,9 +233,426 @@ func TestRunGithubCreateIssue(t *testing.T) {
 		}
 
 		// test
-		_, err := createIssueLocal(ctx, &config, &ghCreateIssueService, nil, nil)
+		_, err := createIssueLocal(ctx, &config, &ghCreateIssueService, nil, nil, nil, nil, nil, nil)
 
 		// assert
 		assert.EqualError(t, err, "error occurred when creating issue: error creating issue")
 	})
 }
+
+type ghListCommentsMock struct {
+	comments [][]*github.IssueComment
+	pages    []int
+}
+
+func (g *ghListCommentsMock) ListComments(ctx context.Context, owner string, repo string, number int, opts *github.IssueListCommentsOptions) ([]*github.IssueComment, *github.Response, error) {
+	g.pages = append(g.pages, opts.Page)
+	page := opts.Page
+	if page == 0 {
+		page = 1
+	}
+	ghRes := github.Response{Response: &http.Response{Status: "200"}, LastPage: len(g.comments)}
+	if page > len(g.comments) {
+		return []*github.IssueComment{}, &ghRes, nil
+	}
+	return g.comments[page-1], &ghRes, nil
+}
+
+type ghGetUserMock struct {
+	login string
+}
+
+func (g *ghGetUserMock) Get(ctx context.Context, user string) (*github.User, *github.Response, error) {
+	return &github.User{Login: &g.login}, &github.Response{Response: &http.Response{Status: "200"}}, nil
+}
+
+type ghCreateReactionMock struct {
+	issueNumber int
+	commentID   int64
+	content     string
+}
+
+func (g *ghCreateReactionMock) CreateIssueReaction(ctx context.Context, owner, repo string, number int, content string) (*github.Reaction, *github.Response, error) {
+	g.issueNumber = number
+	g.content = content
+	return &github.Reaction{Content: &content}, &github.Response{Response: &http.Response{Status: "200"}}, nil
+}
+
+func (g *ghCreateReactionMock) CreateIssueCommentReaction(ctx context.Context, owner, repo string, id int64, content string) (*github.Reaction, *github.Response, error) {
+	g.commentID = id
+	g.content = content
+	return &github.Reaction{Content: &content}, &github.Response{Response: &http.Response{Status: "200"}}, nil
+}
+
+func TestRunGithubCreateIssueUpdateMode(t *testing.T) {
+	ctx := context.Background()
+	t.Parallel()
+
+	newConfig := func(updateMode string) CreateIssueOptions {
+		return CreateIssueOptions{
+			Owner:          "TEST",
+			Repository:     "test",
+			Body:           []byte("This is my test body"),
+			Title:          "This is my title",
+			UpdateExisting: true,
+			UpdateMode:     updateMode,
+		}
+	}
+
+	bot, human := &github.User{Login: github.String("piper-bot")}, &github.User{Login: github.String("jane")}
+
+	t.Run("reaction on latest comment", func(t *testing.T) {
+		var firstID, latestID int64 = 1, 2
+		ghSearchIssuesMock := ghSearchIssuesMock{issueID: 1, issueNumber: 42}
+		ghCreateCommentMock := ghCreateCommentMock{}
+		ghListCommentsMock := ghListCommentsMock{comments: [][]*github.IssueComment{{{ID: &firstID, User: bot}}, {{ID: &latestID, User: bot}}}}
+		ghCreateReactionMock := ghCreateReactionMock{}
+		config := newConfig(UpdateModeReaction)
+
+		_, err := createIssueLocal(ctx, &config, nil, &ghSearchIssuesMock, &ghCreateCommentMock, &ghListCommentsMock, &ghCreateReactionMock, &ghGetUserMock{login: "piper-bot"}, nil)
+
+		assert.NoError(t, err)
+		assert.True(t, config.Existing)
+		assert.Nil(t, ghCreateCommentMock.issueComment)
+		assert.Equal(t, []int{0, 2}, ghListCommentsMock.pages)
+		assert.Equal(t, latestID, ghCreateReactionMock.commentID)
+		assert.Equal(t, "eyes", ghCreateReactionMock.content)
+	})
+
+	t.Run("reaction on latest bot comment followed by a human comment", func(t *testing.T) {
+		var firstID, botID, humanID int64 = 1, 2, 3
+		ghSearchIssuesMock := ghSearchIssuesMock{issueID: 1, issueNumber: 42}
+		ghCreateCommentMock := ghCreateCommentMock{}
+		ghListCommentsMock := ghListCommentsMock{comments: [][]*github.IssueComment{
+			{{ID: &firstID, User: bot}},
+			{{ID: &botID, User: bot}, {ID: &humanID, User: human}},
+		}}
+		ghCreateReactionMock := ghCreateReactionMock{}
+		config := newConfig(UpdateModeReaction)
+
+		_, err := createIssueLocal(ctx, &config, nil, &ghSearchIssuesMock, &ghCreateCommentMock, &ghListCommentsMock, &ghCreateReactionMock, &ghGetUserMock{login: "piper-bot"}, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0, 2}, ghListCommentsMock.pages)
+		assert.Equal(t, botID, ghCreateReactionMock.commentID)
+		assert.Equal(t, 0, ghCreateReactionMock.issueNumber)
+	})
+
+	t.Run("reaction on issue with human comments only", func(t *testing.T) {
+		var humanID int64 = 1
+		ghSearchIssuesMock := ghSearchIssuesMock{issueID: 1, issueNumber: 42}
+		ghCreateCommentMock := ghCreateCommentMock{}
+		ghListCommentsMock := ghListCommentsMock{comments: [][]*github.IssueComment{{{ID: &humanID, User: human}}}}
+		ghCreateReactionMock := ghCreateReactionMock{}
+		config := newConfig(UpdateModeReaction)
+
+		_, err := createIssueLocal(ctx, &config, nil, &ghSearchIssuesMock, &ghCreateCommentMock, &ghListCommentsMock, &ghCreateReactionMock, &ghGetUserMock{login: "piper-bot"}, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), ghCreateReactionMock.commentID)
+		assert.Equal(t, 42, ghCreateReactionMock.issueNumber)
+	})
+
+	t.Run("reaction on issue without comments", func(t *testing.T) {
+		ghSearchIssuesMock := ghSearchIssuesMock{issueID: 1, issueNumber: 42}
+		ghCreateCommentMock := ghCreateCommentMock{}
+		ghListCommentsMock := ghListCommentsMock{}
+		ghCreateReactionMock := ghCreateReactionMock{}
+		config := newConfig(UpdateModeReaction)
+
+		_, err := createIssueLocal(ctx, &config, nil, &ghSearchIssuesMock, &ghCreateCommentMock, &ghListCommentsMock, &ghCreateReactionMock, &ghGetUserMock{login: "piper-bot"}, nil)
+
+		assert.NoError(t, err)
+		assert.Nil(t, ghCreateCommentMock.issueComment)
+		assert.Equal(t, 42, ghCreateReactionMock.issueNumber)
+		assert.Equal(t, "eyes", ghCreateReactionMock.content)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		ghSearchIssuesMock := ghSearchIssuesMock{issueID: 1, issueNumber: 42}
+		ghCreateCommentMock := ghCreateCommentMock{}
+		config := newConfig(UpdateModeNone)
+
+		issue, err := createIssueLocal(ctx, &config, nil, &ghSearchIssuesMock, &ghCreateCommentMock, nil, nil, nil, nil)
+
+		assert.NoError(t, err)
+		assert.True(t, config.Existing)
+		assert.Equal(t, 42, issue.GetNumber())
+		assert.Nil(t, ghCreateCommentMock.issueComment)
+	})
+
+	t.Run("comment template", func(t *testing.T) {
+		ghSearchIssuesMock := ghSearchIssuesMock{issueID: 1, issueNumber: 42}
+		ghCreateCommentMock := ghCreateCommentMock{}
+		config := newConfig(UpdateModeComment)
+		config.CommentTemplate = "Found by {{.Pipeline}} for {{.Commit}}:\n\n{{.Body}}"
+		config.CommentValues = map[string]string{"Pipeline": "my-pipeline", "Commit": "abc123"}
+
+		_, err := createIssueLocal(ctx, &config, nil, &ghSearchIssuesMock, &ghCreateCommentMock, nil, nil, nil, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Found by my-pipeline for abc123:\n\nThis is my test body", ghCreateCommentMock.issueComment.GetBody())
+	})
+
+	t.Run("comment template with unknown value", func(t *testing.T) {
+		ghSearchIssuesMock := ghSearchIssuesMock{issueID: 1, issueNumber: 42}
+		ghCreateCommentMock := ghCreateCommentMock{}
+		config := newConfig(UpdateModeComment)
+		config.CommentTemplate = "{{.Unknown}} {{.Body}}"
+
+		_, err := createIssueLocal(ctx, &config, nil, &ghSearchIssuesMock, &ghCreateCommentMock, nil, nil, nil, nil)
+
+		assert.EqualError(t, err, `failed to render comment template: template: comment:1:2: executing "comment" at <.Unknown>: map has no entry for key "Unknown"`)
+		assert.Nil(t, ghCreateCommentMock.issueComment)
+	})
+}
+
+func TestCreateIssuePaginatedSearch(t *testing.T) {
+	var searchedPages []string
+	var commentedIssue string
+	created := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/search/issues":
+			page := r.URL.Query().Get("page")
+			searchedPages = append(searchedPages, page)
+			w.Header().Set("Content-Type", "application/json")
+			if page == "" {
+				w.Header().Set("Link", fmt.Sprintf(`<%v/search/issues?page=2>; rel="next", <%v/search/issues?page=2>; rel="last"`, "http://"+r.Host, "http://"+r.Host))
+				fmt.Fprint(w, `{"total_count": 2, "items": [{"number": 1, "title": "This is my title (outdated)"}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"total_count": 2, "items": [{"number": 7, "title": "This is my title"}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/TEST/test/issues/7/comments":
+			commentedIssue = "7"
+			fmt.Fprint(w, `{"id": 1}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/TEST/test/issues":
+			created = true
+			fmt.Fprint(w, `{"number": 8}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	issue, err := CreateIssue(&CreateIssueOptions{
+		APIURL:         server.URL,
+		Token:          "token",
+		Owner:          "TEST",
+		Repository:     "test",
+		Title:          "This is my title",
+		Body:           []byte("This is my test body"),
+		UpdateExisting: true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, issue.GetNumber())
+	assert.Equal(t, []string{"", "2"}, searchedPages)
+	assert.Equal(t, "7", commentedIssue)
+	assert.False(t, created, "no duplicate issue must be created")
+}
+
+type ghSearchIssuesRateLimitMock struct {
+	calls int
+}
+
+func (g *ghSearchIssuesRateLimitMock) Issues(ctx context.Context, query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+	g.calls++
+	ghRes := github.Response{Response: &http.Response{Status: "403"}}
+	if g.calls == 1 {
+		return nil, &ghRes, &github.RateLimitError{Rate: github.Rate{Reset: github.Timestamp{Time: time.Now()}}, Response: ghRes.Response}
+	}
+	return &github.IssuesSearchResult{}, &github.Response{Response: &http.Response{Status: "200"}}, nil
+}
+
+func TestSearchExistingIssueRateLimit(t *testing.T) {
+	ghSearchIssuesMock := ghSearchIssuesRateLimitMock{}
+
+	issue, err := searchExistingIssue(context.Background(), "is:open is:issue repo:TEST/test in:title This is my title", func(*github.Issue) bool { return true }, &ghSearchIssuesMock)
+
+	assert.NoError(t, err)
+	assert.Nil(t, issue)
+	assert.Equal(t, 2, ghSearchIssuesMock.calls)
+}
+
+type ghSearchIssuesByQueryMock struct {
+	queries []string
+	results map[string][]*github.Issue
+}
+
+func (g *ghSearchIssuesByQueryMock) Issues(ctx context.Context, query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+	g.queries = append(g.queries, query)
+	return &github.IssuesSearchResult{Issues: g.results[query]}, &github.Response{Response: &http.Response{Status: "200"}}, nil
+}
+
+func TestRunGithubCreateIssueFingerprint(t *testing.T) {
+	ctx := context.Background()
+	t.Parallel()
+
+	marker := FingerprintMarker("whitesource/my-project")
+	bodyQuery := "is:open is:issue repo:TEST/test in:body " + fingerprintHash("whitesource/my-project")
+	titleQuery := "is:open is:issue repo:TEST/test in:title This is my title"
+	newConfig := func() CreateIssueOptions {
+		return CreateIssueOptions{
+			Owner:          "TEST",
+			Repository:     "test",
+			Body:           []byte("This is my test body"),
+			Title:          "This is my title",
+			UpdateExisting: true,
+			Fingerprint:    "whitesource/my-project",
+		}
+	}
+
+	t.Run("marker", func(t *testing.T) {
+		assert.Equal(t, "\n\n<!-- piper-issue-id: "+fingerprintHash("whitesource/my-project")+" -->", marker)
+		assert.Len(t, fingerprintHash("whitesource/my-project"), 16)
+	})
+
+	t.Run("existing issue found by marker", func(t *testing.T) {
+		number := 42
+		renamedTitle := "Renamed title"
+		otherBody := "mentions " + fingerprintHash("whitesource/my-project") + " without marker"
+		markedBody := "old body" + marker
+		other := 41
+		ghSearchIssuesMock := ghSearchIssuesByQueryMock{results: map[string][]*github.Issue{
+			bodyQuery: {{Number: &other, Title: &renamedTitle, Body: &otherBody}, {Number: &number, Title: &renamedTitle, Body: &markedBody}},
+		}}
+		ghCreateCommentMock := ghCreateCommentMock{}
+		config := newConfig()
+
+		issue, err := createIssueLocal(ctx, &config, nil, &ghSearchIssuesMock, &ghCreateCommentMock, nil, nil, nil, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 42, issue.GetNumber())
+		assert.Equal(t, []string{bodyQuery}, ghSearchIssuesMock.queries)
+		assert.Equal(t, 42, ghCreateCommentMock.issueNumber)
+		assert.Equal(t, "This is my test body", ghCreateCommentMock.issueComment.GetBody())
+	})
+
+	t.Run("fallback to title", func(t *testing.T) {
+		number := 43
+		title := "This is my title"
+		ghSearchIssuesMock := ghSearchIssuesByQueryMock{results: map[string][]*github.Issue{
+			titleQuery: {{Number: &number, Title: &title}},
+		}}
+		ghCreateCommentMock := ghCreateCommentMock{}
+		config := newConfig()
+
+		issue, err := createIssueLocal(ctx, &config, nil, &ghSearchIssuesMock, &ghCreateCommentMock, nil, nil, nil, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 43, issue.GetNumber())
+		assert.Equal(t, []string{bodyQuery, titleQuery}, ghSearchIssuesMock.queries)
+	})
+
+	t.Run("new issue contains marker", func(t *testing.T) {
+		ghSearchIssuesMock := ghSearchIssuesByQueryMock{}
+		ghCreateIssueService := ghCreateIssueMock{issueID: 1}
+		config := newConfig()
+
+		_, err := createIssueLocal(ctx, &config, &ghCreateIssueService, &ghSearchIssuesMock, nil, nil, nil, nil, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "This is my test body"+marker, ghCreateIssueService.issue.GetBody())
+	})
+}
+
+func TestCreateIssueByNumber(t *testing.T) {
+	newServer := func(state string, requests *[]string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*requests = append(*requests, r.Method+" "+r.URL.Path)
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/repos/TEST/test/issues/5" && len(state) > 0:
+				fmt.Fprintf(w, `{"number": 5, "state": %q}`, state)
+			case r.Method == http.MethodPatch && r.URL.Path == "/repos/TEST/test/issues/5":
+				fmt.Fprint(w, `{"number": 5, "state": "open"}`)
+			case r.Method == http.MethodPost && r.URL.Path == "/repos/TEST/test/issues/5/comments":
+				fmt.Fprint(w, `{"id": 1}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	}
+	options := func(apiURL string, reopen bool) *CreateIssueOptions {
+		return &CreateIssueOptions{APIURL: apiURL, Owner: "TEST", Repository: "test", Title: "Finding", Body: []byte("details"), IssueNumber: 5, ReopenClosed: reopen}
+	}
+
+	t.Run("open issue is updated without search", func(t *testing.T) {
+		requests := []string{}
+		server := newServer("open", &requests)
+		defer server.Close()
+
+		issue, err := CreateIssue(options(server.URL, false))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, issue.GetNumber())
+		assert.Equal(t, []string{"GET /repos/TEST/test/issues/5", "POST /repos/TEST/test/issues/5/comments"}, requests)
+	})
+
+	t.Run("closed issue is reopened", func(t *testing.T) {
+		requests := []string{}
+		server := newServer("closed", &requests)
+		defer server.Close()
+
+		_, err := CreateIssue(options(server.URL, true))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"GET /repos/TEST/test/issues/5", "PATCH /repos/TEST/test/issues/5", "POST /repos/TEST/test/issues/5/comments"}, requests)
+	})
+
+	t.Run("closed issue is updated without reopening", func(t *testing.T) {
+		requests := []string{}
+		server := newServer("closed", &requests)
+		defer server.Close()
+
+		_, err := CreateIssue(options(server.URL, false))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"GET /repos/TEST/test/issues/5", "POST /repos/TEST/test/issues/5/comments"}, requests)
+	})
+
+	t.Run("issue does not exist", func(t *testing.T) {
+		requests := []string{}
+		server := newServer("", &requests)
+		defer server.Close()
+
+		_, err := CreateIssue(options(server.URL, true))
+
+		assert.EqualError(t, err, "issue #5 does not exist in TEST/test")
+		assert.Equal(t, []string{"GET /repos/TEST/test/issues/5"}, requests)
+	})
+}
+
+func TestNewClient(t *testing.T) {
+	_, client, err := NewClient("token", "https://github.example.com/api/v3", "https://github.example.com/api/uploads", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://github.example.com/api/v3/", client.BaseURL.String())
+	assert.Equal(t, "https://github.example.com/api/uploads/", client.UploadURL.String())
+}
+
+func TestNewClientWithOptions(t *testing.T) {
+	userAgent := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	ctx, client, err := NewClientWithOptions("token", server.URL, "", nil, ClientOptions{Timeout: 50 * time.Millisecond, UserAgent: "piper/1.0 (test)"})
+	assert.NoError(t, err)
+
+	request, err := client.NewRequest(http.MethodGet, "fast", nil)
+	assert.NoError(t, err)
+	_, err = client.Do(ctx, request, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "piper/1.0 (test)", userAgent)
+
+	request, err = client.NewRequest(http.MethodGet, "slow", nil)
+	assert.NoError(t, err)
+	_, err = client.Do(ctx, request, nil)
+	assert.ErrorContains(t, err, "Client.Timeout exceeded")
+}
@@ -0,0 +1,56 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/google/go-github/v45/github"
+	"github.com/pkg/errors"
+)
+
+type githubEditIssueService interface {
+	Get(ctx context.Context, owner string, repo string, number int) (*github.Issue, *github.Response, error)
+	Edit(ctx context.Context, owner string, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error)
+}
+
+// linkToParentIssue adds the issue as task list item to the body of the parent issue, so that GitHub tracks the issue in the parent.
+// A missing parent issue is only reported as warning since the issue itself has been created already.
+func linkToParentIssue(ctx context.Context, ghCreateIssueOptions *CreateIssueOptions, issue *github.Issue, ghEditIssueService githubEditIssueService) error {
+	owner, repository, parentNumber := ghCreateIssueOptions.Owner, ghCreateIssueOptions.Repository, ghCreateIssueOptions.ParentIssue
+
+	parent, resp, err := ghEditIssueService.Get(ctx, owner, repository, parentNumber)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			log.Entry().Warnf("Parent issue #%v not found, issue #%v is not linked to it", parentNumber, issue.GetNumber())
+			return nil
+		}
+		if resp != nil {
+			log.Entry().Errorf("GitHub get issue returned response code %v", resp.Status)
+		}
+		return errors.Wrapf(err, "error occurred when reading parent issue #%v", parentNumber)
+	}
+
+	// the issue might be linked already by a previous run
+	if regexp.MustCompile(fmt.Sprintf(`(?m)^- \[[ xX]\] #%v\s*$`, issue.GetNumber())).MatchString(parent.GetBody()) {
+		log.Entry().Debugf("Issue #%v is already linked to parent issue #%v", issue.GetNumber(), parentNumber)
+		return nil
+	}
+
+	body := parent.GetBody()
+	if len(body) > 0 {
+		body += "\n"
+	}
+	body += fmt.Sprintf("- [ ] #%v", issue.GetNumber())
+	_, resp, err = ghEditIssueService.Edit(ctx, owner, repository, parentNumber, &github.IssueRequest{Body: &body})
+	if err != nil {
+		if resp != nil {
+			log.Entry().Errorf("GitHub edit issue returned response code %v", resp.Status)
+		}
+		return errors.Wrapf(err, "error occurred when linking issue #%v to parent issue #%v", issue.GetNumber(), parentNumber)
+	}
+	log.Entry().Infof("Issue #%v linked to parent issue #%v", issue.GetNumber(), parentNumber)
+	return nil
+}
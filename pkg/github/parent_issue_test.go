@@ -0,0 +1,72 @@
+//go:build unit
+// +build unit
+
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v45/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateIssueWithParentIssue(t *testing.T) {
+	newServer := func(t *testing.T, parentBody string, edited *github.IssueRequest) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodPost && r.URL.Path == "/repos/TEST/test/issues":
+				fmt.Fprint(w, `{"number": 42}`)
+			case r.Method == http.MethodGet && r.URL.Path == "/repos/TEST/test/issues/7" && len(parentBody) > 0:
+				fmt.Fprintf(w, `{"number": 7, "body": %q}`, parentBody)
+			case r.Method == http.MethodPatch && r.URL.Path == "/repos/TEST/test/issues/7":
+				require.NoError(t, json.NewDecoder(r.Body).Decode(edited))
+				fmt.Fprint(w, `{"number": 7}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	}
+	options := func(apiURL string) *CreateIssueOptions {
+		return &CreateIssueOptions{APIURL: apiURL, Owner: "TEST", Repository: "test", Title: "Finding", Body: []byte("details"), ParentIssue: 7}
+	}
+
+	t.Run("issue is added to the task list of the parent", func(t *testing.T) {
+		edited := github.IssueRequest{}
+		server := newServer(t, "Findings of the scan:\n- [x] #41", &edited)
+		defer server.Close()
+
+		issue, err := CreateIssue(options(server.URL))
+
+		require.NoError(t, err)
+		assert.Equal(t, 42, issue.GetNumber())
+		assert.Equal(t, "Findings of the scan:\n- [x] #41\n- [ ] #42", edited.GetBody())
+	})
+
+	t.Run("issue already linked", func(t *testing.T) {
+		edited := github.IssueRequest{}
+		server := newServer(t, "- [ ] #42\n- [ ] #43", &edited)
+		defer server.Close()
+
+		_, err := CreateIssue(options(server.URL))
+
+		require.NoError(t, err)
+		assert.Nil(t, edited.Body)
+	})
+
+	t.Run("parent issue not found", func(t *testing.T) {
+		edited := github.IssueRequest{}
+		server := newServer(t, "", &edited)
+		defer server.Close()
+
+		issue, err := CreateIssue(options(server.URL))
+
+		require.NoError(t, err)
+		assert.Equal(t, 42, issue.GetNumber())
+		assert.Nil(t, edited.Body)
+	})
+}
@@ -161,7 +161,10 @@ func (c *Client) Upload(data UploadRequestData) (*http.Response, error) {
 			c.logger.Debugf("New %v request to %v (binary upload)", data.Method, data.URL)
 			return &http.Response{}, errors.Wrapf(err, "error creating %v request to %v (binary upload)", data.Method, data.URL)
 		}
-		request.Header.Add("Content-Type", "application/octet-stream")
+		// a content type provided by the caller takes precedence
+		if len(request.Header.Get("Content-Type")) == 0 {
+			request.Header.Add("Content-Type", "application/octet-stream")
+		}
 		request.Header.Add("Connection", "Keep-Alive")
 
 		return c.Send(request)
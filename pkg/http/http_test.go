@@ -365,6 +365,31 @@ func TestUploadRequest(t *testing.T) {
 	}
 }
 
+func TestUploadRequestBinaryContentType(t *testing.T) {
+	var passedContentTypes []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		passedContentTypes = req.Header.Values("Content-Type")
+		rw.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	client := Client{logger: log.Entry().WithField("package", "SAP/jenkins-library/pkg/http")}
+	client.SetOptions(ClientOptions{MaxRetries: -1})
+
+	t.Run("default content type", func(t *testing.T) {
+		_, err := client.Upload(UploadRequestData{Method: http.MethodPut, URL: server.URL, FileContent: bytes.NewBufferString("content"), UploadType: "binary"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"application/octet-stream"}, passedContentTypes)
+	})
+
+	t.Run("content type provided by caller", func(t *testing.T) {
+		header := http.Header{"Content-Type": {"application/gzip"}}
+		_, err := client.Upload(UploadRequestData{Method: http.MethodPut, URL: server.URL, FileContent: bytes.NewBufferString("content"), Header: header, UploadType: "binary"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"application/gzip"}, passedContentTypes)
+	})
+}
+
 func TestUploadRequestWrongMethod(t *testing.T) {
 	client := Client{logger: log.Entry().WithField("package", "SAP/jenkins-library/pkg/http")}
 	_, err := client.UploadRequest("GET", "dummy", "testFile", "Field1", nil, nil, "form")
@@ -0,0 +1,131 @@
+package kubernetes
+
+import (
+	"strconv"
+)
+
+// HelmCommandArgs contains the inputs of the helm argument builders which are resolved at runtime rather than configured,
+// e.g. value files which have been downloaded to a temporary directory or flags whose support depends on the helm version
+type HelmCommandArgs struct {
+	// Release is the name of the release, which might differ from DeploymentName, see TruncateReleaseName
+	Release string
+	// Chart is the local chart path or the name of the chart repository
+	Chart string
+	// ValueFiles are passed via --values in the given order, i.e. from lowest to highest precedence
+	ValueFiles []string
+	// Timeout is the value of --timeout, e.g. "300s"
+	Timeout string
+	// Debug adds --debug
+	Debug bool
+	// VersionParams are flags which are only supported by certain helm versions, e.g. --take-ownership
+	VersionParams []string
+}
+
+// valueArgs returns the --values and --set-json arguments in the order of their precedence.
+// They are shared by upgrade, install and diff, so that helm diff compares against the values which are actually deployed.
+func valueArgs(opts HelmExecuteOptions, valueFiles []string) []string {
+	helmParams := []string{}
+	for _, valueFile := range valueFiles {
+		helmParams = append(helmParams, "--values", valueFile)
+	}
+	for _, value := range opts.SetJSONValues {
+		helmParams = append(helmParams, "--set-json", value)
+	}
+	return helmParams
+}
+
+// BuildUpgradeArgs returns the arguments of helm upgrade --install for the options
+func BuildUpgradeArgs(opts HelmExecuteOptions, args HelmCommandArgs) []string {
+	helmParams := []string{"upgrade", args.Release, args.Chart}
+	if args.Debug {
+		helmParams = append(helmParams, "--debug")
+	}
+
+	helmParams = append(helmParams, valueArgs(opts, args.ValueFiles)...)
+
+	helmParams = append(helmParams, "--install", "--namespace", opts.Namespace)
+	if opts.CreateNamespace && !opts.managesNamespace() {
+		helmParams = append(helmParams, "--create-namespace")
+	}
+	if opts.ForceUpdates {
+		helmParams = append(helmParams, "--force")
+	}
+	if opts.ResetValues {
+		helmParams = append(helmParams, "--reset-values")
+	}
+	if opts.ReuseValues {
+		helmParams = append(helmParams, "--reuse-values")
+	}
+
+	helmParams = append(helmParams, "--wait", "--timeout", args.Timeout)
+	if opts.WaitForJobs {
+		helmParams = append(helmParams, "--wait-for-jobs")
+	}
+	if opts.atomic() {
+		helmParams = append(helmParams, "--atomic")
+	}
+	if opts.CleanupOnFail {
+		helmParams = append(helmParams, "--cleanup-on-fail")
+	}
+	if opts.HistoryMax > 0 {
+		helmParams = append(helmParams, "--history-max", strconv.Itoa(opts.HistoryMax))
+	}
+	helmParams = append(helmParams, args.VersionParams...)
+
+	if opts.RenderSubchartNotes {
+		helmParams = append(helmParams, "--render-subchart-notes")
+	}
+	if len(opts.Description) > 0 {
+		helmParams = append(helmParams, "--description", opts.Description)
+	}
+	return append(helmParams, opts.AdditionalParameters...)
+}
+
+// BuildInstallArgs returns the arguments of helm install for the options.
+// CleanupOnFail and HistoryMax are ignored since helm install does not support them.
+func BuildInstallArgs(opts HelmExecuteOptions, args HelmCommandArgs) []string {
+	helmParams := []string{"install", args.Release, args.Chart, "--namespace", opts.Namespace}
+	if !opts.managesNamespace() {
+		helmParams = append(helmParams, "--create-namespace")
+	}
+	if opts.atomic() {
+		helmParams = append(helmParams, "--atomic")
+	}
+	helmParams = append(helmParams, args.VersionParams...)
+
+	helmParams = append(helmParams, "--wait", "--timeout", args.Timeout)
+	if opts.WaitForJobs {
+		helmParams = append(helmParams, "--wait-for-jobs")
+	}
+
+	helmParams = append(helmParams, valueArgs(opts, args.ValueFiles)...)
+
+	if opts.RenderSubchartNotes {
+		helmParams = append(helmParams, "--render-subchart-notes")
+	}
+	if len(opts.Description) > 0 {
+		helmParams = append(helmParams, "--description", opts.Description)
+	}
+	helmParams = append(helmParams, opts.AdditionalParameters...)
+	if args.Debug {
+		helmParams = append(helmParams, "--debug")
+	}
+	return helmParams
+}
+
+// BuildUninstallArgs returns the arguments of helm uninstall for the options.
+// helm only waits for the deletion of the resources if HelmDeployWaitSeconds or UninstallTimeout is configured.
+func BuildUninstallArgs(opts HelmExecuteOptions, args HelmCommandArgs) []string {
+	helmParams := []string{"uninstall", args.Release, "--namespace", opts.Namespace}
+	if opts.KeepHistory {
+		helmParams = append(helmParams, "--keep-history")
+	}
+	helmParams = append(helmParams, args.VersionParams...)
+	if opts.HelmDeployWaitSeconds > 0 || len(opts.UninstallTimeout) > 0 {
+		helmParams = append(helmParams, "--wait", "--timeout", args.Timeout)
+	}
+	if args.Debug {
+		helmParams = append(helmParams, "--debug")
+	}
+	return helmParams
+}
@@ -0,0 +1,139 @@
+//go:build unit
+// +build unit
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildUpgradeArgs(t *testing.T) {
+	atomic := false
+	args := HelmCommandArgs{Release: "my-app", Chart: "./chart", Timeout: "300s"}
+
+	tt := []struct {
+		name     string
+		opts     HelmExecuteOptions
+		args     HelmCommandArgs
+		expected []string
+	}{
+		{
+			name:     "defaults",
+			opts:     HelmExecuteOptions{Namespace: "dev"},
+			args:     args,
+			expected: []string{"upgrade", "my-app", "./chart", "--install", "--namespace", "dev", "--wait", "--timeout", "300s", "--atomic"},
+		},
+		{
+			name: "values in the order of their precedence",
+			opts: HelmExecuteOptions{Namespace: "dev", SetJSONValues: []string{`resources={"limits":{"cpu":"1"}}`}},
+			args: HelmCommandArgs{Release: "my-app", Chart: "./chart", Timeout: "300s", ValueFiles: []string{"values.yaml", "secrets://secrets.yaml"}},
+			expected: []string{"upgrade", "my-app", "./chart", "--values", "values.yaml", "--values", "secrets://secrets.yaml", "--set-json", `resources={"limits":{"cpu":"1"}}`,
+				"--install", "--namespace", "dev", "--wait", "--timeout", "300s", "--atomic"},
+		},
+		{
+			name: "all upgrade flags",
+			opts: HelmExecuteOptions{
+				Namespace:            "dev",
+				CreateNamespace:      true,
+				ForceUpdates:         true,
+				ResetValues:          true,
+				WaitForJobs:          true,
+				Atomic:               &atomic,
+				CleanupOnFail:        true,
+				HistoryMax:           5,
+				RenderSubchartNotes:  true,
+				Description:          "release 1.0",
+				AdditionalParameters: []string{"--skip-crds"},
+			},
+			args: HelmCommandArgs{Release: "my-app", Chart: "stable", Timeout: "10m", Debug: true, VersionParams: []string{"--take-ownership"}},
+			expected: []string{"upgrade", "my-app", "stable", "--debug", "--install", "--namespace", "dev", "--create-namespace", "--force", "--reset-values",
+				"--wait", "--timeout", "10m", "--wait-for-jobs", "--cleanup-on-fail", "--history-max", "5", "--take-ownership",
+				"--render-subchart-notes", "--description", "release 1.0", "--skip-crds"},
+		},
+		{
+			name:     "namespace managed by the step",
+			opts:     HelmExecuteOptions{Namespace: "dev", CreateNamespace: true, ReuseValues: true, NamespaceLabels: map[string]string{"team": "core"}},
+			args:     args,
+			expected: []string{"upgrade", "my-app", "./chart", "--install", "--namespace", "dev", "--reuse-values", "--wait", "--timeout", "300s", "--atomic"},
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, BuildUpgradeArgs(test.opts, test.args))
+		})
+	}
+}
+
+func TestBuildInstallArgs(t *testing.T) {
+	tt := []struct {
+		name     string
+		opts     HelmExecuteOptions
+		args     HelmCommandArgs
+		expected []string
+	}{
+		{
+			name:     "defaults",
+			opts:     HelmExecuteOptions{Namespace: "dev"},
+			args:     HelmCommandArgs{Release: "my-app", Chart: "./chart", Timeout: "300s"},
+			expected: []string{"install", "my-app", "./chart", "--namespace", "dev", "--create-namespace", "--atomic", "--wait", "--timeout", "300s"},
+		},
+		{
+			name: "upgrade only flags are ignored",
+			opts: HelmExecuteOptions{
+				Namespace:             "dev",
+				KeepFailedDeployments: true,
+				CleanupOnFail:         true,
+				HistoryMax:            5,
+				WaitForJobs:           true,
+				Description:           "release 1.0",
+				AdditionalParameters:  []string{"--skip-crds"},
+			},
+			args: HelmCommandArgs{Release: "my-app", Chart: "./chart", Timeout: "300s", ValueFiles: []string{"values.yaml"}, Debug: true, VersionParams: []string{"--labels", "team=core"}},
+			expected: []string{"install", "my-app", "./chart", "--namespace", "dev", "--create-namespace", "--labels", "team=core", "--wait", "--timeout", "300s", "--wait-for-jobs",
+				"--values", "values.yaml", "--description", "release 1.0", "--skip-crds", "--debug"},
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, BuildInstallArgs(test.opts, test.args))
+		})
+	}
+}
+
+func TestBuildUninstallArgs(t *testing.T) {
+	tt := []struct {
+		name     string
+		opts     HelmExecuteOptions
+		args     HelmCommandArgs
+		expected []string
+	}{
+		{
+			name:     "no wait",
+			opts:     HelmExecuteOptions{Namespace: "dev"},
+			args:     HelmCommandArgs{Release: "my-app", Timeout: "0s"},
+			expected: []string{"uninstall", "my-app", "--namespace", "dev"},
+		},
+		{
+			name:     "wait with the deploy timeout",
+			opts:     HelmExecuteOptions{Namespace: "dev", HelmDeployWaitSeconds: 300, KeepHistory: true},
+			args:     HelmCommandArgs{Release: "my-app", Timeout: "300s", Debug: true, VersionParams: []string{"--cascade", "foreground"}},
+			expected: []string{"uninstall", "my-app", "--namespace", "dev", "--keep-history", "--cascade", "foreground", "--wait", "--timeout", "300s", "--debug"},
+		},
+		{
+			name:     "wait with the uninstall timeout",
+			opts:     HelmExecuteOptions{Namespace: "dev", UninstallTimeout: "2m"},
+			args:     HelmCommandArgs{Release: "my-app", Timeout: "2m"},
+			expected: []string{"uninstall", "my-app", "--namespace", "dev", "--wait", "--timeout", "2m"},
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, BuildUninstallArgs(test.opts, test.args))
+		})
+	}
+}
@@ -0,0 +1,201 @@
+//go:build !release
+// +build !release
+
+package mocks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	piperhttp "github.com/SAP/jenkins-library/pkg/http"
+	kubernetes "github.com/SAP/jenkins-library/pkg/kubernetes"
+	"github.com/SAP/jenkins-library/pkg/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// FakeDeployUtils is a fake of kubernetes.DeployUtils for testing the construction of helm and kubectl commands without the binaries.
+// Executed commands are recorded by the embedded ExecMockRunner, files are kept in memory by the embedded FilesMock.
+// HTTP requests are recorded and answered with the configured responses, requests without configured response succeed with status 200.
+type FakeDeployUtils struct {
+	*mock.ExecMockRunner
+	*mock.FilesMock
+
+	// Responses contains the response to requests by their URL, e.g. {"https://my.repo/chart-1.0.0.tgz": {StatusCode: 409, Body: "exists"}}
+	Responses map[string]FakeResponse
+	// RequestErrors contains the error returned for requests by their URL
+	RequestErrors map[string]error
+	// Downloads contains the content of downloaded files by their URL
+	Downloads map[string][]byte
+	// ClientOptions contains the options passed to SetOptions
+	ClientOptions []piperhttp.ClientOptions
+
+	mutex    sync.Mutex
+	requests []FakeRequest
+}
+
+// FakeResponse is a programmed HTTP response of FakeDeployUtils
+type FakeResponse struct {
+	StatusCode int
+	Body       string
+}
+
+// FakeRequest is an HTTP request recorded by FakeDeployUtils
+type FakeRequest struct {
+	Method string
+	URL    string
+	// File is the uploaded file, empty for requests sent with a body
+	File   string
+	Body   []byte
+	Header http.Header
+}
+
+var _ kubernetes.DeployUtils = &FakeDeployUtils{}
+
+// NewFakeDeployUtils creates a FakeDeployUtils without files, canned command outputs or programmed responses
+func NewFakeDeployUtils() *FakeDeployUtils {
+	return &FakeDeployUtils{
+		ExecMockRunner: &mock.ExecMockRunner{},
+		FilesMock:      &mock.FilesMock{},
+		Responses:      map[string]FakeResponse{},
+		RequestErrors:  map[string]error{},
+		Downloads:      map[string][]byte{},
+	}
+}
+
+// Requests returns the recorded HTTP requests in the order they have been sent
+func (f *FakeDeployUtils) Requests() []FakeRequest {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]FakeRequest{}, f.requests...)
+}
+
+// Executions returns the parameters of all calls of the executable, e.g. Executions("helm")
+func (f *FakeDeployUtils) Executions(executable string) [][]string {
+	executions := [][]string{}
+	for _, call := range f.Calls {
+		if call.Exec == executable {
+			executions = append(executions, call.Params)
+		}
+	}
+	return executions
+}
+
+// Executed returns whether the executable has been run with exactly the given parameters
+func (f *FakeDeployUtils) Executed(executable string, params ...string) bool {
+	for _, execution := range f.Executions(executable) {
+		if reflect.DeepEqual(execution, params) {
+			return true
+		}
+	}
+	return false
+}
+
+// AssertExecuted asserts that the executable has been run with exactly the given parameters, e.g.
+// utils.AssertExecuted(t, "helm", "lint", ".")
+func (f *FakeDeployUtils) AssertExecuted(t assert.TestingT, executable string, params ...string) bool {
+	if f.Executed(executable, params...) {
+		return true
+	}
+	return assert.Fail(t, fmt.Sprintf("%v has not been run with parameters %v", executable, params), "calls: %v", f.callsOf(executable))
+}
+
+// AssertNotExecuted asserts that the executable has not been run with a parameter starting with the given prefix, e.g.
+// utils.AssertNotExecuted(t, "helm", "uninstall")
+func (f *FakeDeployUtils) AssertNotExecuted(t assert.TestingT, executable string, prefix ...string) bool {
+	for _, execution := range f.Executions(executable) {
+		if len(execution) >= len(prefix) && reflect.DeepEqual(execution[:len(prefix)], prefix) {
+			return assert.Fail(t, fmt.Sprintf("%v has been run with parameters %v", executable, execution))
+		}
+	}
+	return true
+}
+
+func (f *FakeDeployUtils) callsOf(executable string) string {
+	calls := []string{}
+	for _, execution := range f.Executions(executable) {
+		calls = append(calls, strings.Join(append([]string{executable}, execution...), " "))
+	}
+	return strings.Join(calls, "\n")
+}
+
+// SetOptions records the client options
+func (f *FakeDeployUtils) SetOptions(options piperhttp.ClientOptions) {
+	f.ClientOptions = append(f.ClientOptions, options)
+}
+
+// SendRequest records the request and returns the programmed response
+func (f *FakeDeployUtils) SendRequest(method, url string, body io.Reader, header http.Header, cookies []*http.Cookie) (*http.Response, error) {
+	request := FakeRequest{Method: method, URL: url, Header: header}
+	if body != nil {
+		content, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		request.Body = content
+	}
+	return f.respond(request)
+}
+
+// UploadRequest records the upload and returns the programmed response, the file has to exist in the FilesMock
+func (f *FakeDeployUtils) UploadRequest(method, url, file, fieldName string, header http.Header, cookies []*http.Cookie, uploadType string) (*http.Response, error) {
+	content, err := f.FileRead(file)
+	if err != nil {
+		return nil, err
+	}
+	return f.respond(FakeRequest{Method: method, URL: url, File: file, Body: content, Header: header})
+}
+
+// UploadFile records the upload as PUT request and returns the programmed response
+func (f *FakeDeployUtils) UploadFile(url, file, fieldName string, header http.Header, cookies []*http.Cookie, uploadType string) (*http.Response, error) {
+	return f.UploadRequest(http.MethodPut, url, file, fieldName, header, cookies, uploadType)
+}
+
+// Upload records the upload and returns the programmed response
+func (f *FakeDeployUtils) Upload(data piperhttp.UploadRequestData) (*http.Response, error) {
+	if data.FileContent != nil {
+		content, err := ioutil.ReadAll(data.FileContent)
+		if err != nil {
+			return nil, err
+		}
+		return f.respond(FakeRequest{Method: data.Method, URL: data.URL, File: data.File, Body: content, Header: data.Header})
+	}
+	return f.UploadRequest(data.Method, data.URL, data.File, data.FileFieldName, data.Header, data.Cookies, data.UploadType)
+}
+
+// DownloadFile writes the configured content of the URL to the file
+func (f *FakeDeployUtils) DownloadFile(url, filename string, header http.Header, cookies []*http.Cookie) error {
+	if err := f.RequestErrors[url]; err != nil {
+		return err
+	}
+	content, ok := f.Downloads[url]
+	if !ok {
+		return fmt.Errorf("no content configured for download of %v", url)
+	}
+	return f.FileWrite(filename, content, 0644)
+}
+
+func (f *FakeDeployUtils) respond(request FakeRequest) (*http.Response, error) {
+	f.mutex.Lock()
+	f.requests = append(f.requests, request)
+	f.mutex.Unlock()
+
+	if err := f.RequestErrors[request.URL]; err != nil {
+		return nil, err
+	}
+	response, ok := f.Responses[request.URL]
+	if !ok {
+		response = FakeResponse{StatusCode: http.StatusOK}
+	}
+	return &http.Response{
+		StatusCode: response.StatusCode,
+		Status:     fmt.Sprintf("%d %v", response.StatusCode, http.StatusText(response.StatusCode)),
+		Body:       ioutil.NopCloser(bytes.NewBufferString(response.Body)),
+		Request:    &http.Request{Method: request.Method},
+	}, nil
+}
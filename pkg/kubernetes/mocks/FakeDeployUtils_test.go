@@ -0,0 +1,65 @@
+//go:build unit
+// +build unit
+
+package mocks
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	kubernetes "github.com/SAP/jenkins-library/pkg/kubernetes"
+	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeDeployUtils(t *testing.T) {
+	t.Run("records helm commands", func(t *testing.T) {
+		utils := NewFakeDeployUtils()
+		helmExecutor := kubernetes.NewHelmExecutor(kubernetes.HelmExecuteOptions{
+			ChartPath:  ".",
+			HelmValues: []string{"values.yaml"},
+		}, utils, false, log.Writer())
+
+		_, err := helmExecutor.RunHelmLint()
+
+		assert.NoError(t, err)
+		utils.AssertExecuted(t, "helm", "lint", ".", "--values", "values.yaml")
+		utils.AssertNotExecuted(t, "helm", "upgrade")
+		assert.Equal(t, [][]string{{"lint", ".", "--values", "values.yaml"}}, utils.Executions("helm"))
+		assert.False(t, utils.Executed("helm", "lint", "."))
+	})
+
+	t.Run("programmed upload response", func(t *testing.T) {
+		utils := NewFakeDeployUtils()
+		utils.AddFile("chart-1.0.0.tgz", []byte("chart"))
+		utils.Responses["https://my.repo/chart-1.0.0.tgz"] = FakeResponse{StatusCode: http.StatusConflict, Body: "chart exists"}
+
+		response, err := utils.UploadFile("https://my.repo/chart-1.0.0.tgz", "chart-1.0.0.tgz", "", nil, nil, "binary")
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusConflict, response.StatusCode)
+		}
+		assert.Equal(t, []FakeRequest{{Method: http.MethodPut, URL: "https://my.repo/chart-1.0.0.tgz", File: "chart-1.0.0.tgz", Body: []byte("chart")}}, utils.Requests())
+	})
+
+	t.Run("request error", func(t *testing.T) {
+		utils := NewFakeDeployUtils()
+		utils.RequestErrors["https://my.repo/values.yaml"] = errors.New("connection refused")
+
+		err := utils.DownloadFile("https://my.repo/values.yaml", "values.yaml", nil, nil)
+
+		assert.EqualError(t, err, "connection refused")
+		assert.False(t, utils.HasWrittenFile("values.yaml"))
+	})
+
+	t.Run("download", func(t *testing.T) {
+		utils := NewFakeDeployUtils()
+		utils.Downloads["https://my.repo/values.yaml"] = []byte("replicas: 2")
+
+		assert.NoError(t, utils.DownloadFile("https://my.repo/values.yaml", "values.yaml", nil, nil))
+		content, err := utils.FileRead("values.yaml")
+		assert.NoError(t, err)
+		assert.Equal(t, "replicas: 2", string(content))
+	})
+}
@@ -0,0 +1,148 @@
+//go:build !release
+// +build !release
+
+package mocks
+
+import (
+	"sync"
+
+	kubernetes "github.com/SAP/jenkins-library/pkg/kubernetes"
+)
+
+// FakeHelmExecutor is a fake of kubernetes.HelmExecutor for testing the orchestration of helm commands without helm.
+// It records the called methods and returns the configured results, methods without configured results succeed.
+type FakeHelmExecutor struct {
+	// Errors contains the error returned by a method by its name, e.g. {"RunHelmUpgrade": errors.New("failed")}
+	Errors map[string]error
+	// Outputs contains the string result of a method by its name, e.g. {"RunHelmPublish": "https://my.repo/chart-1.0.0.tgz"}
+	Outputs map[string]string
+	// Releases is returned by RunHelmList
+	Releases []kubernetes.HelmRelease
+	// LintResult is returned by RunHelmLint
+	LintResult *kubernetes.HelmLintResult
+
+	mutex   sync.Mutex
+	calls   []string
+	plugins []kubernetes.HelmPlugin
+}
+
+var _ kubernetes.HelmExecutor = &FakeHelmExecutor{}
+
+// Calls returns the names of the called methods in the order of their calls
+func (f *FakeHelmExecutor) Calls() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]string{}, f.calls...)
+}
+
+// Called returns whether the method with the given name has been called
+func (f *FakeHelmExecutor) Called(method string) bool {
+	for _, call := range f.Calls() {
+		if call == method {
+			return true
+		}
+	}
+	return false
+}
+
+// InstalledPlugins returns the plugins passed to RunHelmPluginInstall
+func (f *FakeHelmExecutor) InstalledPlugins() []kubernetes.HelmPlugin {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]kubernetes.HelmPlugin{}, f.plugins...)
+}
+
+func (f *FakeHelmExecutor) call(method string) (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.calls = append(f.calls, method)
+	return f.Outputs[method], f.Errors[method]
+}
+
+// RunHelmUpgrade records the call and returns the configured error
+func (f *FakeHelmExecutor) RunHelmUpgrade() error {
+	_, err := f.call("RunHelmUpgrade")
+	return err
+}
+
+// RunHelmLint records the call and returns the configured lint result and error
+func (f *FakeHelmExecutor) RunHelmLint() (*kubernetes.HelmLintResult, error) {
+	_, err := f.call("RunHelmLint")
+	if f.LintResult == nil {
+		return &kubernetes.HelmLintResult{}, err
+	}
+	return f.LintResult, err
+}
+
+// RunHelmInstall records the call and returns the configured error
+func (f *FakeHelmExecutor) RunHelmInstall() error {
+	_, err := f.call("RunHelmInstall")
+	return err
+}
+
+// RunHelmUninstall records the call and returns the configured error
+func (f *FakeHelmExecutor) RunHelmUninstall() error {
+	_, err := f.call("RunHelmUninstall")
+	return err
+}
+
+// RunHelmTest records the call and returns the configured error
+func (f *FakeHelmExecutor) RunHelmTest() error {
+	_, err := f.call("RunHelmTest")
+	return err
+}
+
+// RunHelmPublish records the call and returns the configured output and error
+func (f *FakeHelmExecutor) RunHelmPublish() (string, error) {
+	return f.call("RunHelmPublish")
+}
+
+// RunHelmDependency records the call and returns the configured error
+func (f *FakeHelmExecutor) RunHelmDependency() error {
+	_, err := f.call("RunHelmDependency")
+	return err
+}
+
+// RunHelmGetManifest records the call and returns the configured output and error
+func (f *FakeHelmExecutor) RunHelmGetManifest() (string, error) {
+	return f.call("RunHelmGetManifest")
+}
+
+// RunHelmShowValues records the call and returns the configured output and error
+func (f *FakeHelmExecutor) RunHelmShowValues() (string, error) {
+	return f.call("RunHelmShowValues")
+}
+
+// RunHelmDiff records the call and returns the configured output and error
+func (f *FakeHelmExecutor) RunHelmDiff() (string, error) {
+	return f.call("RunHelmDiff")
+}
+
+// RunHelmPluginInstall records the call including the plugins and returns the configured error
+func (f *FakeHelmExecutor) RunHelmPluginInstall(plugins []kubernetes.HelmPlugin) error {
+	_, err := f.call("RunHelmPluginInstall")
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.plugins = append(f.plugins, plugins...)
+	return err
+}
+
+// RunHelmList records the call and returns the configured releases and error
+func (f *FakeHelmExecutor) RunHelmList() ([]kubernetes.HelmRelease, error) {
+	_, err := f.call("RunHelmList")
+	if err != nil {
+		return nil, err
+	}
+	return f.Releases, nil
+}
+
+// GetHelmValues records the call and returns the configured output and error
+func (f *FakeHelmExecutor) GetHelmValues() (string, error) {
+	return f.call("GetHelmValues")
+}
+
+// Cleanup records the call and returns the configured error
+func (f *FakeHelmExecutor) Cleanup() error {
+	_, err := f.call("Cleanup")
+	return err
+}
@@ -0,0 +1,50 @@
+//go:build unit
+// +build unit
+
+package mocks
+
+import (
+	"errors"
+	"testing"
+
+	kubernetes "github.com/SAP/jenkins-library/pkg/kubernetes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeHelmExecutor(t *testing.T) {
+	t.Run("records calls and returns configured results", func(t *testing.T) {
+		helmExecutor := &FakeHelmExecutor{
+			Errors:  map[string]error{"RunHelmTest": errors.New("test failed")},
+			Outputs: map[string]string{"RunHelmPublish": "https://my.repo/chart-1.0.0.tgz"},
+		}
+
+		assert.NoError(t, helmExecutor.RunHelmUpgrade())
+		assert.EqualError(t, helmExecutor.RunHelmTest(), "test failed")
+		targetURL, err := helmExecutor.RunHelmPublish()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://my.repo/chart-1.0.0.tgz", targetURL)
+		assert.Equal(t, []string{"RunHelmUpgrade", "RunHelmTest", "RunHelmPublish"}, helmExecutor.Calls())
+		assert.True(t, helmExecutor.Called("RunHelmTest"))
+		assert.False(t, helmExecutor.Called("RunHelmInstall"))
+	})
+
+	t.Run("default results", func(t *testing.T) {
+		helmExecutor := &FakeHelmExecutor{}
+
+		lintResult, err := helmExecutor.RunHelmLint()
+		assert.NoError(t, err)
+		assert.NotNil(t, lintResult)
+		releases, err := helmExecutor.RunHelmList()
+		assert.NoError(t, err)
+		assert.Empty(t, releases)
+	})
+
+	t.Run("installed plugins", func(t *testing.T) {
+		helmExecutor := &FakeHelmExecutor{}
+		plugins := []kubernetes.HelmPlugin{{Name: "diff"}}
+
+		assert.NoError(t, helmExecutor.RunHelmPluginInstall(plugins))
+		assert.Equal(t, plugins, helmExecutor.InstalledPlugins())
+	})
+}
@@ -2,13 +2,51 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	kubernetes "github.com/SAP/jenkins-library/pkg/kubernetes"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // HelmExecutor is an autogenerated mock type for the HelmExecutor type
 type HelmExecutor struct {
 	mock.Mock
 }
 
+// Cleanup provides a mock function with given fields:
+func (_m *HelmExecutor) Cleanup() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetHelmValues provides a mock function with given fields:
+func (_m *HelmExecutor) GetHelmValues() (string, error) {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RunHelmDependency provides a mock function with given fields:
 func (_m *HelmExecutor) RunHelmDependency() error {
 	ret := _m.Called()
@@ -23,6 +61,48 @@ func (_m *HelmExecutor) RunHelmDependency() error {
 	return r0
 }
 
+// RunHelmDiff provides a mock function with given fields:
+func (_m *HelmExecutor) RunHelmDiff() (string, error) {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunHelmGetManifest provides a mock function with given fields:
+func (_m *HelmExecutor) RunHelmGetManifest() (string, error) {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RunHelmInstall provides a mock function with given fields:
 func (_m *HelmExecutor) RunHelmInstall() error {
 	ret := _m.Called()
@@ -38,12 +118,58 @@ func (_m *HelmExecutor) RunHelmInstall() error {
 }
 
 // RunHelmLint provides a mock function with given fields:
-func (_m *HelmExecutor) RunHelmLint() error {
+func (_m *HelmExecutor) RunHelmLint() (*kubernetes.HelmLintResult, error) {
 	ret := _m.Called()
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func() error); ok {
+	var r0 *kubernetes.HelmLintResult
+	if rf, ok := ret.Get(0).(func() *kubernetes.HelmLintResult); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*kubernetes.HelmLintResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunHelmList provides a mock function with given fields:
+func (_m *HelmExecutor) RunHelmList() ([]kubernetes.HelmRelease, error) {
+	ret := _m.Called()
+
+	var r0 []kubernetes.HelmRelease
+	if rf, ok := ret.Get(0).(func() []kubernetes.HelmRelease); ok {
 		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]kubernetes.HelmRelease)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunHelmPluginInstall provides a mock function with given fields: plugins
+func (_m *HelmExecutor) RunHelmPluginInstall(plugins []kubernetes.HelmPlugin) error {
+	ret := _m.Called(plugins)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]kubernetes.HelmPlugin) error); ok {
+		r0 = rf(plugins)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -72,6 +198,27 @@ func (_m *HelmExecutor) RunHelmPublish() (string, error) {
 	return r0, r1
 }
 
+// RunHelmShowValues provides a mock function with given fields:
+func (_m *HelmExecutor) RunHelmShowValues() (string, error) {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RunHelmTest provides a mock function with given fields:
 func (_m *HelmExecutor) RunHelmTest() error {
 	ret := _m.Called()
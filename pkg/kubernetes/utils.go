@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/SAP/jenkins-library/pkg/command"
 	piperhttp "github.com/SAP/jenkins-library/pkg/http"
@@ -16,9 +17,11 @@ import (
 // DeployUtils interface
 type DeployUtils interface {
 	SetEnv(env []string)
+	SetDir(dir string)
 	Stdout(out io.Writer)
 	Stderr(err io.Writer)
 	RunExecutable(e string, p ...string) error
+	RunExecutableInBackground(e string, p ...string) (command.Execution, error)
 
 	piperutils.FileUtils
 	piperhttp.Uploader
@@ -46,23 +49,8 @@ func NewDeployUtilsBundle(customTLSCertificateLinks []string) DeployUtils {
 
 	utils := deployUtilsBundle{
 		Command: &command.Command{
-			ErrorCategoryMapping: map[string][]string{
-				log.ErrorConfiguration.String(): {
-					"Error: Get * no such host",
-					"Error: path * not found",
-					"Error: rendered manifests contain a resource that already exists.",
-					"Error: unknown flag",
-					"Error: UPGRADE FAILED: * failed to replace object: * is invalid",
-					"Error: UPGRADE FAILED: * failed to create resource: * is invalid",
-					"Error: UPGRADE FAILED: an error occurred * not found",
-					"Error: UPGRADE FAILED: query: failed to query with labels:",
-					"Invalid value: \"\": field is immutable",
-				},
-				log.ErrorCustom.String(): {
-					"Error: release * failed, * timed out waiting for the condition",
-				},
-			},
-			StepName: "helmExecute",
+			ErrorCategoryMapping: helmErrorCategoryMapping(),
+			StepName:             "helmExecute",
 		},
 		Files:  &piperutils.Files{},
 		Client: &piperhttp.Client{},
@@ -72,6 +60,126 @@ func NewDeployUtilsBundle(customTLSCertificateLinks []string) DeployUtils {
 	return &utils
 }
 
+// helmErrorSignatures maps signatures of helm failures to error categories, '*' matches any text.
+// classifyHelmError checks the signatures in order, i.e. more specific signatures have to be listed before generic ones.
+// Generic signatures also match failures of more specific signatures of other categories. They are not passed to command.Command
+// since it checks its ErrorCategoryMapping in random order, which would categorize these failures nondeterministically.
+var helmErrorSignatures = []struct {
+	category   log.ErrorCategory
+	signatures []string
+	generic    bool
+}{
+	{category: log.ErrorConfiguration, signatures: []string{
+		"Error: Get * no such host",
+		"Error: path * not found",
+		"Error: rendered manifests contain a resource that already exists.",
+		"Error: unknown flag",
+		"Error: UPGRADE FAILED: * failed to replace object: * is invalid",
+		"Error: UPGRADE FAILED: * failed to create resource: * is invalid",
+		"Error: UPGRADE FAILED: an error occurred * not found",
+		"Error: UPGRADE FAILED: query: failed to query with labels:",
+		"Invalid value: \"\": field is immutable",
+		// kube context and credentials
+		"Kubernetes cluster unreachable: context * does not exist",
+		"Kubernetes cluster unreachable: invalid configuration",
+		"You must be logged in to the server (Unauthorized)",
+		"is forbidden: User * cannot",
+		"401 Unauthorized",
+		"403 Forbidden",
+		// images which cannot be pulled
+		"ErrImagePull",
+		"ImagePullBackOff",
+		"InvalidImageName",
+	}},
+	{category: log.ErrorCustom, signatures: []string{
+		"Error: release * failed, * timed out waiting for the condition",
+	}},
+	{category: log.ErrorInfrastructure, signatures: []string{
+		"context deadline exceeded",
+		"connection refused",
+		"connection reset by peer",
+		"i/o timeout",
+		"TLS handshake timeout",
+		"http2: client connection lost",
+		"the server is currently unable to handle the request",
+		"etcdserver: request timed out",
+		"another operation (install/upgrade/rollback) is in progress",
+	}},
+	{category: log.ErrorInfrastructure, generic: true, signatures: []string{
+		"timed out waiting for the condition",
+		"Kubernetes cluster unreachable",
+		"is not a valid chart repository or cannot be reached",
+	}},
+}
+
+// helmErrorCategoryMapping returns the signatures of helm failures in the format of command.Command.ErrorCategoryMapping, except for the generic ones
+func helmErrorCategoryMapping() map[string][]string {
+	mapping := map[string][]string{}
+	for _, entry := range helmErrorSignatures {
+		if entry.generic {
+			continue
+		}
+		mapping[entry.category.String()] = append(mapping[entry.category.String()], entry.signatures...)
+	}
+	return mapping
+}
+
+// transientHelmErrorSignatures are infrastructure failures of the connection to the cluster which usually vanish when repeating the call.
+// Failures like "timed out waiting for the condition" are not transient since they are typically caused by the deployed resources.
+var transientHelmErrorSignatures = []string{
+	"connection refused",
+	"connection reset by peer",
+	"i/o timeout",
+	"TLS handshake timeout",
+	"http2: client connection lost",
+	"the server is currently unable to handle the request",
+	"etcdserver: request timed out",
+	"failed calling webhook * context deadline exceeded",
+}
+
+// transientHelmError returns the transient error signature which matches a line of the helm output or an empty string if the failure is not transient.
+// Failures categorized as configuration errors, e.g. an unreachable cluster due to an invalid kube context, are never transient.
+func transientHelmError(output string) string {
+	if classifyHelmError(output) != log.ErrorInfrastructure {
+		return ""
+	}
+	for _, line := range strings.Split(output, "\n") {
+		for _, signature := range transientHelmErrorSignatures {
+			if matchSignature(line, signature) {
+				return signature
+			}
+		}
+	}
+	return ""
+}
+
+// classifyHelmError returns the error category of the first signature which matches a line of the helm output
+func classifyHelmError(output string) log.ErrorCategory {
+	lines := strings.Split(output, "\n")
+	for _, entry := range helmErrorSignatures {
+		for _, signature := range entry.signatures {
+			for _, line := range lines {
+				if matchSignature(line, signature) {
+					return entry.category
+				}
+			}
+		}
+	}
+	return log.ErrorUndefined
+}
+
+// matchSignature checks whether all parts of signature separated by '*' are contained in line in the given order
+func matchSignature(line, signature string) bool {
+	for _, part := range strings.Split(signature, "*") {
+		index := strings.Index(line, part)
+		if index < 0 {
+			return false
+		}
+		line = line[index+len(part):]
+	}
+	return true
+}
+
 // GetChartInfo is used to get name and version of helm chart
 func GetChartInfo(chartYamlFile string, utils DeployUtils) (string, string, error) {
 
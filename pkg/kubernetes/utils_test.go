@@ -7,6 +7,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/SAP/jenkins-library/pkg/log"
 	"github.com/SAP/jenkins-library/pkg/mock"
 	"github.com/stretchr/testify/assert"
 )
@@ -88,3 +89,147 @@ func TestRunUtils(t *testing.T) {
 		}
 	})
 }
+
+func TestClassifyHelmError(t *testing.T) {
+	tt := []struct {
+		name     string
+		output   string
+		expected log.ErrorCategory
+	}{
+		{
+			name:     "atomic release timeout",
+			output:   "Error: release my-app failed, and has been uninstalled due to atomic being set: timed out waiting for the condition",
+			expected: log.ErrorCustom,
+		},
+		{
+			name:     "upgrade timeout",
+			output:   "Error: UPGRADE FAILED: timed out waiting for the condition",
+			expected: log.ErrorInfrastructure,
+		},
+		{
+			name:     "context not found",
+			output:   "Error: Kubernetes cluster unreachable: context \"prod\" does not exist",
+			expected: log.ErrorConfiguration,
+		},
+		{
+			name:     "cluster unreachable",
+			output:   "Error: Kubernetes cluster unreachable: Get \"https://10.0.0.1:6443/version\": dial tcp 10.0.0.1:6443: i/o timeout",
+			expected: log.ErrorInfrastructure,
+		},
+		{
+			name:     "unauthorized",
+			output:   "Error: Kubernetes cluster unreachable: the server has asked for the client to provide credentials\nerror: You must be logged in to the server (Unauthorized)",
+			expected: log.ErrorConfiguration,
+		},
+		{
+			name:     "forbidden",
+			output:   "Error: UPGRADE FAILED: secrets is forbidden: User \"system:serviceaccount:ci:deployer\" cannot list resource \"secrets\" in API group \"\" in the namespace \"prod\"",
+			expected: log.ErrorConfiguration,
+		},
+		{
+			name:     "chart repository authentication",
+			output:   "Error: looks like \"https://charts.example.com\" is not a valid chart repository or cannot be reached: failed to fetch https://charts.example.com/index.yaml : 401 Unauthorized",
+			expected: log.ErrorConfiguration,
+		},
+		{
+			name:     "chart repository not reachable",
+			output:   "Error: looks like \"https://charts.example.com\" is not a valid chart repository or cannot be reached: Get \"https://charts.example.com/index.yaml\": dial tcp: lookup charts.example.com: connection refused",
+			expected: log.ErrorInfrastructure,
+		},
+		{
+			name:     "image pull",
+			output:   "Warning  Failed  pod/my-app-5d4f7  Error: ImagePullBackOff",
+			expected: log.ErrorConfiguration,
+		},
+		{
+			name:     "pending operation",
+			output:   "Error: UPGRADE FAILED: another operation (install/upgrade/rollback) is in progress",
+			expected: log.ErrorInfrastructure,
+		},
+		{
+			name:     "immutable field",
+			output:   "Error: UPGRADE FAILED: cannot patch \"my-app\" with kind Deployment: Deployment.apps \"my-app\" is invalid: spec.selector: Invalid value: \"\": field is immutable",
+			expected: log.ErrorConfiguration,
+		},
+		{
+			name:     "unknown error",
+			output:   "Error: something unexpected happened",
+			expected: log.ErrorUndefined,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, classifyHelmError(test.output))
+		})
+	}
+}
+
+func TestTransientHelmError(t *testing.T) {
+	tt := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{
+			name:     "connection refused",
+			output:   "Error: UPGRADE FAILED: Kubernetes cluster unreachable: Get \"https://10.0.0.1:6443/version\": dial tcp 10.0.0.1:6443: connect: connection refused",
+			expected: "connection refused",
+		},
+		{
+			name:     "webhook timeout",
+			output:   "Error: UPGRADE FAILED: Internal error occurred: failed calling webhook \"validate.nginx.ingress.kubernetes.io\": Post \"https://ingress-nginx-controller-admission.ingress-nginx.svc:443/networking/v1/ingresses?timeout=10s\": context deadline exceeded",
+			expected: "failed calling webhook * context deadline exceeded",
+		},
+		{
+			name:     "API server unavailable",
+			output:   "Error: UPGRADE FAILED: the server is currently unable to handle the request (get deployments.apps my-app)",
+			expected: "the server is currently unable to handle the request",
+		},
+		{
+			name:   "invalid kube context",
+			output: "Error: Kubernetes cluster unreachable: context \"prod\" does not exist",
+		},
+		{
+			name:   "rollout timeout",
+			output: "Error: UPGRADE FAILED: timed out waiting for the condition",
+		},
+		{
+			name:   "pending operation",
+			output: "Error: UPGRADE FAILED: another operation (install/upgrade/rollback) is in progress",
+		},
+		{
+			name:   "unknown error",
+			output: "Error: something unexpected happened",
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, transientHelmError(test.output))
+		})
+	}
+}
+
+func TestHelmErrorCategoryMapping(t *testing.T) {
+	mapping := helmErrorCategoryMapping()
+	assert.Contains(t, mapping[log.ErrorConfiguration.String()], "Error: unknown flag")
+	assert.Equal(t, []string{"Error: release * failed, * timed out waiting for the condition"}, mapping[log.ErrorCustom.String()])
+	assert.Contains(t, mapping[log.ErrorInfrastructure.String()], "connection refused")
+
+	// generic signatures overlapping with specific ones of other categories are only used by classifyHelmError
+	assert.NotContains(t, mapping[log.ErrorInfrastructure.String()], "Kubernetes cluster unreachable")
+	assert.NotContains(t, mapping[log.ErrorInfrastructure.String()], "timed out waiting for the condition")
+	for category, signatures := range mapping {
+		for _, signature := range signatures {
+			for otherCategory, otherSignatures := range mapping {
+				if otherCategory == category {
+					continue
+				}
+				for _, otherSignature := range otherSignatures {
+					assert.False(t, matchSignature(otherSignature, signature), "signature '%v' (%v) matches '%v' (%v)", signature, category, otherSignature, otherCategory)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package reporting
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HelmDiffReportType identifies a HelmDiffReport in a report file
+const HelmDiffReportType = "helmDiff"
+
+// HelmDiffReportSchemaVersion is the version of the HelmDiffReport file format, it is increased for incompatible changes only
+const HelmDiffReportSchemaVersion = 1
+
+// HelmDiffReport contains the changes an upgrade of a helm release would apply to the cluster.
+// It is written by helmExecute and can be used as body of a GitHub issue by githubCreateIssue.
+type HelmDiffReport struct {
+	Type          string    `json:"type"`
+	SchemaVersion int       `json:"schemaVersion"`
+	Release       string    `json:"release"`
+	Namespace     string    `json:"namespace"`
+	Chart         string    `json:"chart"`
+	Changes       bool      `json:"changes"`
+	Diff          string    `json:"diff"`
+	ReportTime    time.Time `json:"reportTime"`
+}
+
+// NewHelmDiffReport creates a report of the diff of a release
+func NewHelmDiffReport(release, namespace, chart, diff string) HelmDiffReport {
+	return HelmDiffReport{
+		Type:          HelmDiffReportType,
+		SchemaVersion: HelmDiffReportSchemaVersion,
+		Release:       release,
+		Namespace:     namespace,
+		Chart:         chart,
+		Changes:       len(strings.TrimSpace(diff)) > 0,
+		Diff:          diff,
+		ReportTime:    time.Now().UTC(),
+	}
+}
+
+// Title returns the title of the report
+func (r HelmDiffReport) Title() string {
+	return fmt.Sprintf("Helm diff of release %v in namespace %v", r.Release, r.Namespace)
+}
+
+// ToMarkdown creates a markdown version of the report with the diff as code block
+func (r HelmDiffReport) ToMarkdown() ([]byte, error) {
+	md := strings.Builder{}
+	fmt.Fprintf(&md, "## %v\n\n", r.Title())
+	fmt.Fprintf(&md, "Chart: `%v`\n\n", r.Chart)
+	if !r.Changes {
+		md.WriteString("The upgrade does not change any resources.\n")
+	} else {
+		// the fence must be longer than any backtick sequence within the diff
+		fence := "```"
+		for strings.Contains(r.Diff, fence) {
+			fence += "`"
+		}
+		fmt.Fprintf(&md, "%vdiff\n%v\n%v\n", fence, strings.TrimRight(r.Diff, "\n"), fence)
+	}
+	fmt.Fprintf(&md, "\nReport time: %v\n", r.ReportTime.Format("Jan 02, 2006 - 15:04:05 MST"))
+	return []byte(md.String()), nil
+}
+
+// ToTxt creates a text version of the report
+func (r HelmDiffReport) ToTxt() string {
+	if !r.Changes {
+		return fmt.Sprintf("%v\nChart: %v\n\nThe upgrade does not change any resources.\n", r.Title(), r.Chart)
+	}
+	return fmt.Sprintf("%v\nChart: %v\n\n%v", r.Title(), r.Chart, r.Diff)
+}
+
+// ToJSON returns the report as JSON
+func (r HelmDiffReport) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// ParseIssueDetail reads a report file written by a step, e.g. a HelmDiffReport, based on its type
+func ParseIssueDetail(content []byte) (IssueDetail, error) {
+	header := struct {
+		Type          string `json:"type"`
+		SchemaVersion int    `json:"schemaVersion"`
+	}{}
+	if err := json.Unmarshal(content, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse report: %w", err)
+	}
+
+	switch header.Type {
+	case HelmDiffReportType:
+		if header.SchemaVersion > HelmDiffReportSchemaVersion {
+			return nil, fmt.Errorf("unsupported schema version %v of report type '%v', the latest supported version is %v", header.SchemaVersion, header.Type, HelmDiffReportSchemaVersion)
+		}
+		report := HelmDiffReport{}
+		if err := json.Unmarshal(content, &report); err != nil {
+			return nil, fmt.Errorf("failed to parse report: %w", err)
+		}
+		return report, nil
+	default:
+		return nil, fmt.Errorf("unsupported report type '%v'", header.Type)
+	}
+}
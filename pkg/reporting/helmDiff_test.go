@@ -0,0 +1,78 @@
+//go:build unit
+// +build unit
+
+package reporting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelmDiffReport(t *testing.T) {
+	reportTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("markdown with changes", func(t *testing.T) {
+		report := NewHelmDiffReport("my-app", "dev", "./chart", "default, my-app, Deployment (apps) has changed:\n-  replicas: 1\n+  replicas: 2\n")
+		report.ReportTime = reportTime
+
+		markdown, err := report.ToMarkdown()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "## Helm diff of release my-app in namespace dev\n\nChart: `./chart`\n\n```diff\ndefault, my-app, Deployment (apps) has changed:\n-  replicas: 1\n+  replicas: 2\n```\n\nReport time: Jan 02, 2024 - 03:04:05 UTC\n", string(markdown))
+	})
+
+	t.Run("markdown without changes", func(t *testing.T) {
+		report := NewHelmDiffReport("my-app", "dev", "./chart", "\n")
+		report.ReportTime = reportTime
+
+		markdown, err := report.ToMarkdown()
+
+		assert.NoError(t, err)
+		assert.False(t, report.Changes)
+		assert.Contains(t, string(markdown), "The upgrade does not change any resources.")
+		assert.NotContains(t, string(markdown), "```")
+	})
+
+	t.Run("diff containing a code fence", func(t *testing.T) {
+		report := NewHelmDiffReport("my-app", "dev", "./chart", "+  readme: |\n+    ```\n")
+
+		markdown, err := report.ToMarkdown()
+
+		assert.NoError(t, err)
+		assert.Contains(t, string(markdown), "````diff\n+  readme: |\n+    ```\n````\n")
+	})
+
+	t.Run("JSON round trip", func(t *testing.T) {
+		report := NewHelmDiffReport("my-app", "dev", "./chart", "+  replicas: 2\n")
+		report.ReportTime = reportTime
+
+		content, err := report.ToJSON()
+		require.NoError(t, err)
+		parsed, err := ParseIssueDetail(content)
+
+		assert.NoError(t, err)
+		assert.Equal(t, report, parsed)
+		assert.Contains(t, string(content), `"type": "helmDiff"`)
+		assert.Contains(t, string(content), `"schemaVersion": 1`)
+	})
+}
+
+func TestParseIssueDetail(t *testing.T) {
+	t.Run("unsupported type", func(t *testing.T) {
+		_, err := ParseIssueDetail([]byte(`{"type": "unknown"}`))
+		assert.EqualError(t, err, "unsupported report type 'unknown'")
+	})
+
+	t.Run("unsupported schema version", func(t *testing.T) {
+		_, err := ParseIssueDetail([]byte(`{"type": "helmDiff", "schemaVersion": 2}`))
+		assert.EqualError(t, err, "unsupported schema version 2 of report type 'helmDiff', the latest supported version is 1")
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, err := ParseIssueDetail([]byte(`# markdown`))
+		assert.ErrorContains(t, err, "failed to parse report")
+	})
+}